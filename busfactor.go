@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// defaultBusThreshold is the share of the total score the smallest group of
+// contributors must exceed (see --bus-threshold).
+const defaultBusThreshold = 0.5
+
+// busFactor is the knowledge concentration of one scope: the fewest
+// contributors whose combined score exceeds the threshold share of the total.
+type busFactor struct {
+	Scope        string   `json:"scope"` // "(overall)" or a repository path
+	BusFactor    int      `json:"bus_factor"`
+	Contributors []string `json:"contributors"` // Strongest first
+	Share        float64  `json:"share"`        // Combined share of the contributors
+}
+
+// overallScope labels the bus factor across every analyzed repository.
+const overallScope = "(overall)"
+
+// computeBusFactor takes contributors from the strongest down until their
+// combined score exceeds threshold of the total.
+func computeBusFactor(scope string, scores map[string]float64, threshold float64) busFactor {
+	emails := make([]string, 0, len(scores))
+	total := 0.0
+	for email, score := range scores {
+		emails = append(emails, email)
+		total += score
+	}
+	sort.Slice(emails, func(i, j int) bool {
+		if scores[emails[i]] != scores[emails[j]] {
+			return scores[emails[i]] > scores[emails[j]]
+		}
+		return emails[i] < emails[j]
+	})
+	bf := busFactor{Scope: scope, Contributors: []string{}}
+	sum := 0.0
+	for _, email := range emails {
+		if total > 0 && sum/total > threshold {
+			break
+		}
+		sum += scores[email]
+		bf.Contributors = append(bf.Contributors, email)
+	}
+	bf.BusFactor = len(bf.Contributors)
+	if total > 0 {
+		bf.Share = sum / total
+	}
+	return bf
+}
+
+// busFactors reports the bus factor across the whole ranking (final scores,
+// including the cross-repository bonus) and then per repository (decayed
+// scores within that repository), sorted by path.
+func busFactors(owners []owner.OwnerScore, data *owner.Data, threshold float64) []busFactor {
+	overall := make(map[string]float64, len(owners))
+	for _, o := range owners {
+		overall[o.Email] = o.Score
+	}
+	perRepo := make(map[string]map[string]float64)
+	for email, repos := range data.RepoScores {
+		for repo, score := range repos {
+			if perRepo[repo] == nil {
+				perRepo[repo] = make(map[string]float64)
+			}
+			perRepo[repo][email] = score
+		}
+	}
+	repos := make([]string, 0, len(perRepo))
+	for repo := range perRepo {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	reports := []busFactor{computeBusFactor(overallScope, overall, threshold)}
+	for _, repo := range repos {
+		reports = append(reports, computeBusFactor(repo, perRepo[repo], threshold))
+	}
+	return reports
+}
+
+// printBusFactorText prints one line per scope.
+func printBusFactorText(reports []busFactor, threshold float64) {
+	fmt.Printf("\n--- Bus Factor (contributors holding more than %.0f%% of the score) ---\n", threshold*100)
+	for _, bf := range reports {
+		fmt.Printf("%s: %d (%s; %.0f%%)\n", bf.Scope, bf.BusFactor, strings.Join(bf.Contributors, ", "), bf.Share*100)
+	}
+}
+
+// printBusFactorMarkdown prints the reports as a Markdown table.
+func printBusFactorMarkdown(reports []busFactor, threshold float64) {
+	fmt.Printf("**Bus factor** (contributors holding more than %.0f%% of the score)\n\n", threshold*100)
+	fmt.Println("| Scope | Bus Factor | Contributors | Share |")
+	fmt.Println("|---|---|---|---|")
+	for _, bf := range reports {
+		fmt.Printf("| %s | %d | %s | %.0f%% |\n", escapeMarkdownCell(bf.Scope), bf.BusFactor, escapeMarkdownCell(strings.Join(bf.Contributors, ", ")), bf.Share*100)
+	}
+}
+
+// printBusFactorJSON prints the reports as a JSON array.
+func printBusFactorJSON(reports []busFactor) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
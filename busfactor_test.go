@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestComputeBusFactor(t *testing.T) {
+	dominated := computeBusFactor("x", map[string]float64{"a@x": 8, "b@x": 1, "c@x": 1}, defaultBusThreshold)
+	if dominated.BusFactor != 1 || dominated.Contributors[0] != "a@x" || dominated.Share != 0.8 {
+		t.Errorf("dominated repository: got %+v, want bus factor 1 (a@x, 80%%)", dominated)
+	}
+	even := computeBusFactor("x", map[string]float64{"a@x": 1, "b@x": 1, "c@x": 1, "d@x": 1}, defaultBusThreshold)
+	if even.BusFactor != 3 {
+		t.Errorf("evenly split repository: got %+v, want bus factor 3 (more than half of 4 equal shares)", even)
+	}
+	if empty := computeBusFactor("x", nil, defaultBusThreshold); empty.BusFactor != 0 {
+		t.Errorf("empty repository: got %+v, want bus factor 0", empty)
+	}
+}
+
+func TestBusFactorReport(t *testing.T) {
+	dominated := testrepo.New(t)
+	for i := 0; i < 9; i++ {
+		dominated.Commit("solo@example.com", testrepo.DaysAgo(1), nil)
+	}
+	dominated.Commit("helper@example.com", testrepo.DaysAgo(1), nil)
+	even := testrepo.New(t)
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		even.Commit(email, testrepo.DaysAgo(1), nil)
+	}
+
+	var reports []busFactor
+	out := mustRun(t, "--bus-factor", "--format=json", dominated.Dir, even.Dir)
+	if err := json.Unmarshal([]byte(out), &reports); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	got := make(map[string]int)
+	for _, r := range reports {
+		got[r.Scope] = r.BusFactor
+	}
+	if got[dominated.Dir] != 1 || got[even.Dir] != 2 {
+		t.Errorf("got bus factors %v, want 1 for the dominated repository and 2 for the even one", got)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// defaultCodeownersMargin is the relative score margin within which an
+// existing CODEOWNERS assignment is kept (see --codeowners-margin).
+const defaultCodeownersMargin = 0.2
+
+// codeownersRule is one pattern line of a CODEOWNERS file.
+type codeownersRule struct {
+	Pattern gitignore.Pattern
+	Owners  []string // Handles (with the leading @) or emails, as written
+}
+
+// loadCodeowners parses a CODEOWNERS file. Patterns use gitignore syntax and
+// the last matching rule wins, as on GitHub and GitLab. Section headers
+// ("[Section]") and comments are skipped.
+func loadCodeowners(filePath string) ([]codeownersRule, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []codeownersRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+			continue
+		}
+		fields := strings.Fields(line)
+		rules = append(rules, codeownersRule{
+			Pattern: gitignore.ParsePattern(fields[0], nil),
+			Owners:  fields[1:],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read CODEOWNERS file %s: %w", filePath, err)
+	}
+	return rules, nil
+}
+
+// codeownersOwner returns the first owner listed by the last rule matching
+// path, or "" if no rule matches or the matching rule lists no owners. A path
+// ending in "/" is matched as a directory.
+func codeownersOwner(rules []codeownersRule, path string) string {
+	isDir := strings.HasSuffix(path, "/")
+	parts := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Pattern.Match(parts, isDir) != gitignore.NoMatch {
+			if len(rules[i].Owners) == 0 {
+				return ""
+			}
+			return rules[i].Owners[0]
+		}
+	}
+	return ""
+}
+
+// codeownersEntry is the owner assigned to one file in generated CODEOWNERS output.
+type codeownersEntry struct {
+	Path  string
+	Owner string // @handle or email
+	Kept  bool   // The prior assignment was kept for stability
+}
+
+// headFiles lists the files in the tree at rev (HEAD if empty) of a
+// repository, so files that were deleted in the past are left out of
+// generated CODEOWNERS.
+func headFiles(repoPath, rev string) (map[string]struct{}, error) {
+	repo, head, err := owner.OpenRepo(repoPath, rev)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(head)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]struct{})
+	err = tree.Files().ForEach(func(f *object.File) error {
+		paths[f.Name] = struct{}{}
+		return nil
+	})
+	return paths, err
+}
+
+// codeownersDirs sums the weights of the files in existing into every
+// directory up to depth levels below the root, keyed as "dir/" paths, so the
+// generated CODEOWNERS gets one line per directory. A directory's weights
+// cover its whole subtree; its deeper directories follow it in the output and
+// override it, as the last matching rule wins. Files at the root keep their
+// own line. It returns the grouped weights and the set of grouped paths.
+func codeownersDirs(files map[owner.FileKey]map[string]float64, existing map[string]struct{}, depth int) (map[owner.FileKey]map[string]float64, map[string]struct{}) {
+	dirs := make(map[owner.FileKey]map[string]float64)
+	paths := make(map[string]struct{})
+	add := func(key owner.FileKey, weights map[string]float64) {
+		if _, ok := dirs[key]; !ok {
+			dirs[key] = make(map[string]float64)
+			paths[key.Path] = struct{}{}
+		}
+		for email, w := range weights {
+			dirs[key][email] += w
+		}
+	}
+	for key, weights := range files {
+		if _, ok := existing[key.Path]; !ok {
+			continue
+		}
+		parts := strings.Split(key.Path, "/")
+		if len(parts) == 1 {
+			add(key, weights)
+			continue
+		}
+		for i := 1; i < len(parts) && i <= depth; i++ {
+			add(owner.FileKey{Repo: key.Repo, Path: strings.Join(parts[:i], "/") + "/"}, weights)
+		}
+	}
+	return dirs, paths
+}
+
+// assignCodeowners picks an owner for every tracked file. Without prior
+// rules this is the file's top owner. With them, the currently listed owner
+// is kept as long as their score on the file is within margin (relative) of
+// the top owner's, so the file only changes hands when the new owner clearly
+// dominates. Files missing from existing are skipped.
+func assignCodeowners(files map[owner.FileKey]map[string]float64, existing map[string]struct{}, usernames map[string]string, prior []codeownersRule, margin float64) []codeownersEntry {
+	var entries []codeownersEntry
+	for _, top := range topFileOwners(files) {
+		key := top.Key
+		if _, ok := existing[key.Path]; !ok {
+			continue
+		}
+		entry := codeownersEntry{Path: key.Path, Owner: reviewerHandle(top.Email, usernames)}
+		if current := codeownersOwner(prior, key.Path); current != "" && current != entry.Owner {
+			for email, weight := range files[key] {
+				if (email == current || reviewerHandle(email, usernames) == current) && weight >= (1-margin)*top.Score {
+					entry.Owner, entry.Kept = current, true
+					break
+				}
+			}
+		} else if current != "" {
+			entry.Kept = true
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// printCodeowners prints CODEOWNERS lines, one file or directory per line, and reports on
+// stderr how many assignments were kept from the prior file.
+func printCodeowners(entries []codeownersEntry, prior []codeownersRule) {
+	kept := 0
+	for _, entry := range entries {
+		fmt.Printf("/%s %s\n", entry.Path, entry.Owner)
+		if entry.Kept {
+			kept++
+		}
+	}
+	if prior != nil {
+		owner.Infof("Kept %d existing assignments; %d paths changed owner or were new.", kept, len(entries)-kept)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestCodeownersDominantOwnerPerDirectory(t *testing.T) {
+	r := ownersRepo(t)
+	first := mustRun(t, "--format=codeowners", r.Dir)
+	want := "/README.md alice@example.com\n" +
+		"/docs/ bob@example.com\n" +
+		"/svc/ alice@example.com\n"
+	if first != want {
+		t.Errorf("got:\n%s\nwant:\n%s", first, want)
+	}
+	for i := 0; i < 3; i++ {
+		if again := mustRun(t, "--format=codeowners", r.Dir); again != first {
+			t.Fatalf("output changed between runs:\n%s\nthen:\n%s", first, again)
+		}
+	}
+}
+
+func TestCodeownersPerFile(t *testing.T) {
+	r := ownersRepo(t)
+	got := mustRun(t, "--format=codeowners", "--codeowners-depth=0", r.Dir)
+	want := "/README.md alice@example.com\n" +
+		"/docs/guide.md bob@example.com\n" +
+		"/svc/api/handler.go alice@example.com\n" +
+		"/svc/api/main.go alice@example.com\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// Config is a --config file: every top-level key names a flag (tau = 180,
+// exclude-bots = true, exclude-email = ["ci@example.com"], ...), an optional
+// [aliases] table has the layout of an aliases file, and optional
+// [repos."<pattern>"] tables override settings per repository.
+type Config struct {
+	Flags   map[string]any          // flag name -> TOML value
+	Aliases map[string][]string     // canonical_email -> [alias1, alias2, ...]
+	Repos   map[string]repoOverride // path or URL pattern -> overrides
+}
+
+// repoOverride is a [repos."<pattern>"] table: settings for the repositories
+// whose path or URL matches the pattern, in place of the global flags.
+type repoOverride struct {
+	Tau          *float64 `toml:"tau"`
+	BonusPerRepo *float64 `toml:"bonus-per-repo"`
+	Weight       *float64 `toml:"weight"`
+}
+
+// loadConfig reads and parses a config file.
+func loadConfig(filePath string) (*Config, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", filePath, err)
+	}
+	var raw map[string]any
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w: %w", filePath, owner.ErrParse, err)
+	}
+
+	config := &Config{Flags: raw}
+	if _, ok := raw["aliases"]; ok {
+		delete(raw, "aliases")
+		var section owner.AliasConfig
+		if _, err := toml.Decode(string(data), &section); err != nil {
+			return nil, fmt.Errorf("failed to parse [aliases] in config file %s: %w: %w", filePath, owner.ErrParse, err)
+		}
+		config.Aliases = section.Aliases
+	}
+	if _, ok := raw["repos"]; ok {
+		delete(raw, "repos")
+		var section struct {
+			Repos map[string]repoOverride `toml:"repos"`
+		}
+		meta, err := toml.Decode(string(data), &section)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse [repos] in config file %s: %w: %w", filePath, owner.ErrParse, err)
+		}
+		for _, key := range meta.Undecoded() {
+			if len(key) > 2 && key[0] == "repos" {
+				return nil, fmt.Errorf("unknown setting %q for repositories matching %q in config file %s", key[2], key[1], filePath)
+			}
+		}
+		for pattern, o := range section.Repos {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid repository pattern %q in config file %s: %w", pattern, filePath, err)
+			}
+			if (o.Tau != nil && *o.Tau <= 0) || (o.BonusPerRepo != nil && *o.BonusPerRepo < 0) || (o.Weight != nil && *o.Weight < 0) {
+				return nil, fmt.Errorf("repositories matching %q in config file %s: tau must be positive, bonus-per-repo and weight not negative", pattern, filePath)
+			}
+		}
+		config.Repos = section.Repos
+	}
+	return config, nil
+}
+
+// repoOverrides resolves the [repos] tables for every repository: args are
+// the paths or URLs as given and repoPaths where they were scanned from. A
+// repository takes the settings of the longest matching pattern.
+func (c *Config) repoOverrides(args, repoPaths []string) (tau, bonus, weight map[string]float64) {
+	tau, bonus, weight = make(map[string]float64), make(map[string]float64), make(map[string]float64)
+	patterns := make([]string, 0, len(c.Repos))
+	for pattern := range c.Repos {
+		patterns = append(patterns, pattern)
+	}
+	for i, arg := range args {
+		best := matchRepoPattern(patterns, arg)
+		if best == "" {
+			continue
+		}
+		if o := c.Repos[best]; o.Tau != nil {
+			tau[repoPaths[i]] = *o.Tau
+		}
+		if o := c.Repos[best]; o.BonusPerRepo != nil {
+			bonus[repoPaths[i]] = *o.BonusPerRepo
+		}
+		if o := c.Repos[best]; o.Weight != nil {
+			weight[repoPaths[i]] = *o.Weight
+		}
+	}
+	return tau, bonus, weight
+}
+
+// matchRepoPattern returns the longest of patterns matching a repository
+// argument, or "" if none does. Local paths also match by absolute path.
+func matchRepoPattern(patterns []string, arg string) string {
+	names := []string{arg}
+	if abs, err := filepath.Abs(arg); err == nil && !isRemoteURL(arg) {
+		names = append(names, abs)
+	}
+	best := ""
+	for _, pattern := range patterns {
+		if len(pattern) < len(best) || (len(pattern) == len(best) && pattern > best) {
+			continue
+		}
+		for _, name := range names {
+			if ok, _ := filepath.Match(pattern, name); ok {
+				best = pattern
+				break
+			}
+		}
+	}
+	return best
+}
+
+// parseRepoWeights parses --repo-weight values of the form pattern=weight,
+// where pattern is matched like a [repos] table name.
+func parseRepoWeights(specs []string) (map[string]float64, error) {
+	weights := make(map[string]float64, len(specs))
+	for _, spec := range specs {
+		i := strings.LastIndex(spec, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("%q is not of the form pattern=weight", spec)
+		}
+		pattern := spec[:i]
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+		}
+		w, err := strconv.ParseFloat(spec[i+1:], 64)
+		if err != nil || w < 0 || math.IsInf(w, 0) || math.IsNaN(w) {
+			return nil, fmt.Errorf("invalid weight in %q: must be a non-negative number", spec)
+		}
+		weights[pattern] = w
+	}
+	return weights, nil
+}
+
+// apply sets every flag named in the config that was not given on the
+// command line, so explicit flags override the file. Repeatable flags take a
+// list; a list given on the command line replaces the config's.
+func (c *Config) apply(fs *flag.FlagSet) error {
+	explicit := make(map[string]struct{})
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = struct{}{} })
+
+	names := make([]string, 0, len(c.Flags))
+	for name := range c.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Report errors deterministically
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil || name == "config" {
+			return fmt.Errorf("unknown setting %q", name)
+		}
+		if _, ok := explicit[name]; ok {
+			continue
+		}
+		values, isList := c.Flags[name].([]any)
+		if _, repeatable := f.Value.(*stringList); isList != repeatable {
+			if repeatable {
+				return fmt.Errorf("setting %q must be a list", name)
+			}
+			return fmt.Errorf("setting %q must be a single value", name)
+		}
+		if !isList {
+			values = []any{c.Flags[name]}
+		}
+		for _, value := range values {
+			text, err := configValue(value)
+			if err != nil {
+				return fmt.Errorf("setting %q: %w", name, err)
+			}
+			if err := fs.Set(name, text); err != nil {
+				return fmt.Errorf("setting %q: invalid value %q: %w", name, text, err)
+			}
+		}
+	}
+	return nil
+}
+
+// configValue renders a TOML scalar the way it would be typed as a flag value.
+func configValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// writeConfig writes a --config file and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "gitowner.toml")
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// emails returns the first field of every line of a compact report.
+func emails(out string) []string {
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		got = append(got, strings.Fields(line)[0])
+	}
+	return got
+}
+
+func TestConfigFile(t *testing.T) {
+	r := ownersRepo(t)
+	config := writeConfig(t, `
+count = 1
+format = "compact"
+exclude-email = ["bob@example.com"]
+
+[aliases]
+"alice@example.com" = ["carol@example.com"]
+`)
+	tests := []struct {
+		flags []string
+		want  string
+	}{
+		{nil, "alice@example.com"},
+		{[]string{"--count=5"}, "alice@example.com"}, // Carol is merged into alice
+		{[]string{"--exclude-email=alice@example.com"}, "bob@example.com"},
+		{[]string{"--count=5", "--exclude-email=nobody@example.com"}, "bob@example.com alice@example.com"},
+	}
+	for _, tt := range tests {
+		args := append([]string{"--config=" + config}, tt.flags...)
+		out := mustRun(t, append(args, r.Dir)...)
+		if got := strings.Join(emails(out), " "); got != tt.want {
+			t.Errorf("%v: got %s, want %s", tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestInvalidConfigFile(t *testing.T) {
+	r := ownersRepo(t)
+	for content, want := range map[string]string{
+		"tau = ":                  "failed to parse config file",
+		"no-such-flag = 1":        `unknown setting "no-such-flag"`,
+		`exclude-email = "a@b.c"`: `setting "exclude-email" must be a list`,
+		`tau = "soon"`:            `setting "tau": invalid value "soon"`,
+	} {
+		res := run(t, "--config="+writeConfig(t, content), r.Dir)
+		if res.Code != exitUsage || !strings.Contains(res.Stderr, want) {
+			t.Errorf("%q: exit code %d, stderr %q, want %q", content, res.Code, res.Stderr, want)
+		}
+	}
+}
+
+func TestPerRepoOverrides(t *testing.T) {
+	archived, active := testrepo.New(t), testrepo.New(t)
+	archived.Commit("old@example.com", testrepo.DaysAgo(365), nil)
+	archived.Commit("both@example.com", testrepo.DaysAgo(365), nil)
+	active.Commit("new@example.com", testrepo.DaysAgo(365), nil)
+	active.Commit("both@example.com", testrepo.DaysAgo(365), nil)
+	// Both repositories are temporary directories under the same parent, so
+	// the first table matches both
+	config := writeConfig(t, fmt.Sprintf(`
+[repos.%q]
+tau = 36.5
+bonus-per-repo = 0.5
+
+[repos.%q]
+tau = 3650
+`, filepath.Dir(archived.Dir)+"/*", archived.Dir))
+
+	owners := decodeJSON(t, mustRun(t, "--config="+config, "--format=json", archived.Dir, active.Dir))
+	got := make(map[string]owner.OwnerScore)
+	for _, o := range owners {
+		got[o.Email] = o
+	}
+	// The archived repository's own table is the longest match, so it decays slowly
+	want := map[string]float64{
+		"old@example.com": math.Exp(-0.1),
+		"new@example.com": math.Exp(-10),
+	}
+	for email, score := range want {
+		if !near(got[email].Score, score) {
+			t.Errorf("%s scored %g, want %g", email, got[email].Score, score)
+		}
+	}
+	// The active repository adds its own bonus to owners based in the archived one
+	if both := got["both@example.com"]; !near(both.RawScore, math.Exp(-0.1)+math.Exp(-10)) || !near(both.Score, 1.5*both.RawScore) {
+		t.Errorf("got %+v, want bonus 1.5 from the active repository", both)
+	}
+}
+
+func TestParseRepoWeights(t *testing.T) {
+	got, err := parseRepoWeights([]string{"services/core=2", "https://host/org/*=0.5", "a=b=1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{"services/core": 2, "https://host/org/*": 0.5, "a=b": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, spec := range []string{"core", "=2", "core=-1", "core=NaN", "core=x", "[=1"} {
+		if _, err := parseRepoWeights([]string{spec}); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
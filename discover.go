@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// discoverRepos walks root and returns every directory containing a .git
+// entry (a directory, or a file for worktrees and submodules) and every bare
+// repository, in lexical order. Descent stops at each repository found, so nested repositories and
+// worktrees are not counted twice. maxDepth bounds how many levels below root
+// are searched (0 = unlimited). Symbolic links are not followed, so link
+// loops cannot trap the walk.
+func discoverRepos(root string, maxDepth int) ([]string, error) {
+	var repos []string
+	root = filepath.Clean(root)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			owner.Warnf("Warning: skipping %s: %v", path, err)
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil || isBareRepo(path) {
+			repos = append(repos, path)
+			return filepath.SkipDir
+		}
+		if path != root && maxDepth > 0 && strings.Count(path[len(root):], string(filepath.Separator)) >= maxDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return repos, err
+}
+
+// isBareRepo reports whether dir looks like a bare repository: a HEAD file
+// next to objects and refs directories, as git itself checks.
+func isBareRepo(dir string) bool {
+	if info, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || info.IsDir() {
+		return false
+	}
+	for _, sub := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// expandRecursive replaces every directory argument by the repositories found
+// under it. URLs and other arguments are kept as given.
+func expandRecursive(args []string, maxDepth int) []string {
+	var paths []string
+	for _, arg := range args {
+		if isRemoteURL(arg) {
+			paths = append(paths, arg)
+			continue
+		}
+		if info, err := os.Stat(arg); err != nil || !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		repos, err := discoverRepos(arg, maxDepth)
+		if err != nil {
+			exitf(exitUsage, "Error searching %s for repositories: %v", arg, err)
+		}
+		if len(repos) == 0 {
+			owner.Warnf("Warning: no Git repositories found under %s.", arg)
+		}
+		paths = append(paths, repos...)
+	}
+	return paths
+}
+
+// collectRepoArgs merges the repository arguments with the lists read from
+// --repos-from and from a "-" argument (stdin), dropping duplicates while
+// keeping the first occurrence's position. Stdin can only be read once, so
+// stdinTaken reports another flag (--files-from -) already claiming it.
+func collectRepoArgs(args []string, reposFrom string, stdinTaken bool) ([]string, error) {
+	var sources []string
+	if reposFrom != "" {
+		sources = append(sources, reposFrom)
+	}
+	var paths []string
+	for _, arg := range args {
+		if arg == "-" {
+			sources = append(sources, "-")
+			continue
+		}
+		paths = append(paths, arg)
+	}
+	for _, source := range sources {
+		if source == "-" {
+			if stdinTaken {
+				return nil, fmt.Errorf("stdin can only supply one list (repositories or --files-from)")
+			}
+			stdinTaken = true
+		}
+		listed, err := readPathList(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read repository list %s: %w", source, err)
+		}
+		paths = append(paths, listed...)
+	}
+
+	seen := make(map[string]struct{}, len(paths))
+	unique := paths[:0]
+	for _, path := range paths {
+		key := path
+		if !isRemoteURL(path) {
+			key = filepath.Clean(path)
+		}
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, path)
+	}
+	return unique, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestDiscoverRepos(t *testing.T) {
+	root := t.TempDir()
+	testrepo.NewAt(t, filepath.Join(root, "a"))
+	testrepo.NewAt(t, filepath.Join(root, "a", "nested")) // Inside a: not reported again
+	testrepo.NewAt(t, filepath.Join(root, "group", "b"))
+	if err := os.MkdirAll(filepath.Join(root, "notes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes", "todo.txt"), []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := discoverRepos(root, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(root, "a"), filepath.Join(root, "group", "b")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got, err = discoverRepos(root, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(root, "a")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("--max-depth 1: got %v, want %v", got, want)
+	}
+}
+
+func TestRecursiveScan(t *testing.T) {
+	root := t.TempDir()
+	testrepo.NewAt(t, filepath.Join(root, "a")).Commit("a@example.com", testrepo.DaysAgo(1), nil)
+	testrepo.NewAt(t, filepath.Join(root, "group", "b")).Commit("b@example.com", testrepo.DaysAgo(1), nil)
+	if err := os.MkdirAll(filepath.Join(root, "notes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	res := run(t, "--recursive", "--format=json", root)
+	if res.Code != 0 || strings.Contains(res.Stderr, "Skipping repository") {
+		t.Errorf("exit code %d, stderr %q", res.Code, res.Stderr)
+	}
+	owners := decodeJSON(t, res.Stdout)
+	if len(owners) != 2 || owners[0].RepoCount != 1 || owners[1].RepoCount != 1 {
+		t.Errorf("got %+v, want a and b scored in one repository each", owners)
+	}
+}
+
+func TestCollectRepoArgs(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "repos.txt")
+	content := "# Services\n/srv/api\n\n  /srv/web  \n./local\n# /srv/old\n/srv/api/\nhttps://example.com/org/repo.git\n"
+	if err := os.WriteFile(list, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := collectRepoArgs([]string{"/srv/web", "local"}, list, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/srv/web", "local", "/srv/api", "https://example.com/org/repo.git"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := collectRepoArgs([]string{"-"}, "-", false); err == nil {
+		t.Error("expected an error when two lists read stdin")
+	}
+	if _, err := collectRepoArgs(nil, filepath.Join(t.TempDir(), "missing"), false); err == nil {
+		t.Error("expected an error for a missing list")
+	}
+}
+
+func TestReposFromStdinAndFile(t *testing.T) {
+	a, b := testrepo.New(t), testrepo.New(t)
+	a.Commit("a@example.com", testrepo.DaysAgo(1), nil)
+	b.Commit("b@example.com", testrepo.DaysAgo(1), nil)
+	list := filepath.Join(t.TempDir(), "repos.txt")
+	if err := os.WriteFile(list, []byte("# first\n"+a.Dir+"\n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res := runIn(t, t.TempDir(), b.Dir+"\n"+a.Dir+"\n", "--format=json", "--repos-from="+list, "-", a.Dir)
+	if res.Code != 0 {
+		t.Fatalf("exit code %d\n%s", res.Code, res.Stderr)
+	}
+	if owners := decodeJSON(t, res.Stdout); len(owners) != 2 {
+		t.Errorf("got %+v, want a and b", owners)
+	}
+	if n := strings.Count(res.Stderr, "Processing repository"); n != 2 {
+		t.Errorf("processed %d repositories, want 2:\n%s", n, res.Stderr)
+	}
+}
+
+func TestDiscoverBareRepos(t *testing.T) {
+	root := t.TempDir()
+	if _, err := git.PlainInit(filepath.Join(root, "mirror.git"), true); err != nil {
+		t.Fatal(err)
+	}
+	testrepo.NewAt(t, filepath.Join(root, "work"))
+	got, err := discoverRepos(root, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(root, "mirror.git"), filepath.Join(root, "work")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRunFromSubdirectory(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(1), map[string]string{"src/pkg/a.go": "a\n"})
+	r.Commit("bob@example.com", testrepo.DaysAgo(2), map[string]string{"docs/b.md": "b\n"})
+	sub := filepath.Join(r.Dir, "src", "pkg")
+
+	res := runIn(t, sub, "", "--format=json", ".", "..")
+	if res.Code != 0 {
+		t.Fatalf("exit code %d\n%s", res.Code, res.Stderr)
+	}
+	// Both arguments are the same repository, which is scored as a whole
+	owners := decodeJSON(t, res.Stdout)
+	if len(owners) != 2 || strings.Count(res.Stderr, "Processing repository") != 1 {
+		t.Errorf("got %+v, want alice and bob from 1 repository\n%s", owners, res.Stderr)
+	}
+	if res := runIn(t, sub, "", "--no-detect-dotgit", "."); res.Code != exitAllReposFailed {
+		t.Errorf("--no-detect-dotgit: exit code %d, want %d", res.Code, exitAllReposFailed)
+	}
+}
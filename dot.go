@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// coEdit is an undirected edge between two owners who changed the same files.
+type coEdit struct {
+	A, B    string
+	Overlap float64
+}
+
+// coEditEdges computes co-contribution edges between the given emails. The
+// overlap of two owners on a file is the smaller of their weights on it, so an
+// edge is only heavy when both people did substantial recent work there.
+func coEditEdges(emails []string, files map[owner.FileKey]map[string]float64) []coEdit {
+	overlaps := make(map[[2]string]float64)
+	for _, weights := range files {
+		for i, a := range emails {
+			wa, ok := weights[a]
+			if !ok {
+				continue
+			}
+			for _, b := range emails[i+1:] {
+				if wb, ok := weights[b]; ok {
+					overlaps[[2]string{a, b}] += math.Min(wa, wb)
+				}
+			}
+		}
+	}
+
+	edges := make([]coEdit, 0, len(overlaps))
+	for pair, overlap := range overlaps {
+		edges = append(edges, coEdit{A: pair[0], B: pair[1], Overlap: overlap})
+	}
+	// Sort for deterministic output
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].A != edges[j].A {
+			return edges[i].A < edges[j].A
+		}
+		return edges[i].B < edges[j].B
+	})
+	return edges
+}
+
+// printDot renders the owners as an undirected Graphviz graph. Node size is
+// proportional to score and edge thickness to the co-edit overlap.
+// The scoring parameters are recorded as comments at the top of the graph.
+func printDot(owners []owner.OwnerScore, files map[owner.FileKey]map[string]float64, params []parameter) {
+	emails := make([]string, len(owners))
+	maxScore := 0.0
+	for i, owner := range owners {
+		emails[i] = owner.Email
+		maxScore = math.Max(maxScore, owner.Score)
+	}
+	edges := coEditEdges(emails, files)
+	maxOverlap := 0.0
+	for _, e := range edges {
+		maxOverlap = math.Max(maxOverlap, e.Overlap)
+	}
+
+	fmt.Println("graph gitowner {")
+	printParametersDot(params)
+	fmt.Println("  node [shape=circle, fixedsize=true, fontsize=10];")
+	for _, owner := range owners {
+		width := 0.5
+		if maxScore > 0 {
+			width += 1.5 * owner.Score / maxScore
+		}
+		fmt.Printf("  %q [label=%q, width=%.2f];\n", owner.Email, fmt.Sprintf("%s\n%.2f", owner.Email, owner.Score), width)
+	}
+	for _, e := range edges {
+		penwidth := 1.0
+		if maxOverlap > 0 {
+			penwidth += 4 * e.Overlap / maxOverlap
+		}
+		fmt.Printf("  %q -- %q [weight=%.2f, penwidth=%.2f, label=\"%.2f\"];\n", e.A, e.B, e.Overlap, penwidth, e.Overlap)
+	}
+	fmt.Println("}")
+}
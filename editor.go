@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// fileOwner is the strongest owner of one file.
+type fileOwner struct {
+	Key   owner.FileKey
+	Email string
+	Score float64
+}
+
+// topFileOwners picks the highest-weighted owner of every tracked file, with
+// ties broken by email. The result is sorted by repository and path.
+func topFileOwners(files map[owner.FileKey]map[string]float64) []fileOwner {
+	result := make([]fileOwner, 0, len(files))
+	for key, weights := range files {
+		best := fileOwner{Key: key}
+		for email, weight := range weights {
+			if weight > best.Score || (weight == best.Score && email < best.Email) {
+				best.Email, best.Score = email, weight
+			}
+		}
+		if best.Email != "" {
+			result = append(result, best)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Key.Repo != result[j].Key.Repo {
+			return result[i].Key.Repo < result[j].Key.Repo
+		}
+		return result[i].Key.Path < result[j].Key.Path
+	})
+	return result
+}
+
+// printEditor prints one "path:owner_email:score" line per file, a layout
+// editors already know how to parse from compiler and grep output. Paths are
+// joined with the repository argument so they resolve from the working
+// directory. Requested
+// paths that matched no commits are reported on stderr.
+func printEditor(files map[owner.FileKey]map[string]float64, requested []string) {
+	owners := topFileOwners(files)
+	for _, owner := range owners {
+		fmt.Printf("%s:%s:%.2f\n", filepath.Join(owner.Key.Repo, owner.Key.Path), owner.Email, owner.Score)
+	}
+	for _, path := range requested {
+		found := false
+		for key := range files {
+			if owner.PathInScope(key.Path, path) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			owner.Infof("No commits found for %s.", path)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Process exit codes. Every exit goes through exitf so the scheme stays consistent.
+const (
+	exitOK             = 0 // Success (also: no data found, unless --error-on-empty)
+	exitUsage          = 1 // Invalid flags, arguments, or input files
+	exitAllReposFailed = 2 // Every repository failed to process
+	exitPartialFailure = 3 // Results were produced but some repositories were skipped
+	exitEmptyResult    = 4 // No commit data found and --error-on-empty was given
+	exitLowCoverage    = 5 // Fewer commits than --min-coverage were scored and --strict-coverage was given
+	exitLowBusFactor   = 6 // The overall bus factor is below --fail-under-bus-factor
+)
+
+// exitCodesHelp documents the exit codes in --help output.
+const exitCodesHelp = `Exit codes:
+  0  success (or no data found without --error-on-empty or --strict)
+  1  usage error: invalid flags, arguments, or input files
+  2  every repository failed to process
+  3  partial failure: results printed but some repositories were skipped
+  4  no commit data found and --error-on-empty or --strict was given
+  5  fewer commits than --min-coverage were scored and --strict-coverage was given
+     (results are still printed; a partial failure takes precedence)
+  6  the overall bus factor is below --fail-under-bus-factor
+     (results are still printed; codes 3 and 5 take precedence)
+`
+
+// cleanups run before the process exits, however it exits.
+var cleanups []func()
+
+// atExit registers f to run before the process exits (e.g. removing temporary clones).
+func atExit(f func()) {
+	cleanups = append(cleanups, f)
+}
+
+// runCleanups runs the registered cleanups once, most recent first.
+func runCleanups() {
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+	cleanups = nil
+}
+
+// exitf prints a message to stderr and exits with the given code.
+func exitf(code int, format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	runCleanups()
+	os.Exit(code)
+}
+
+// exitOnPartialFailure exits with exitPartialFailure if any repository was skipped.
+func exitOnPartialFailure(failed []string) {
+	if len(failed) > 0 {
+		exitf(exitPartialFailure, "Warning: %d repositories were skipped.", len(failed))
+	}
+}
+
+// exitOnLowCoverage exits with exitLowCoverage if fewer than minCoverage commits were scored.
+func exitOnLowCoverage(scored, minCoverage int) {
+	if scored < minCoverage {
+		exitf(exitLowCoverage, "Warning: only %d commits were scored (--min-coverage=%d); exiting with --strict-coverage.", scored, minCoverage)
+	}
+}
+
+// exitOnLowBusFactor exits with exitLowBusFactor if the bus factor is below minimum.
+func exitOnLowBusFactor(bf busFactor, minimum int) {
+	if bf.BusFactor < minimum {
+		exitf(exitLowBusFactor, "Warning: the overall bus factor is %d, below --fail-under-bus-factor=%d.", bf.BusFactor, minimum)
+	}
+}
+
+// usage prints the command synopsis, the flag defaults and the exit codes.
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] <repo_path_or_url1> [repo_path_or_url2] ...\n\nFlags:\n", os.Args[0])
+	flag.PrintDefaults()
+	fmt.Fprintf(out, "\n%s", exitCodesHelp)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestExitCodes(t *testing.T) {
+	r := ownersRepo(t) // Six commits; bob alone holds over half the score
+	empty := testrepo.New(t)
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{r.Dir}, exitOK},
+		{[]string{empty.Dir}, exitOK},
+		{[]string{"--error-on-empty", empty.Dir}, exitEmptyResult},
+		{[]string{"--strict", empty.Dir}, exitEmptyResult},
+		{[]string{"--no-such-flag", r.Dir}, exitUsage},
+		{[]string{"--format=xml", r.Dir}, exitUsage},
+		{[]string{missing}, exitAllReposFailed},
+		{[]string{r.Dir, missing}, exitPartialFailure},
+		{[]string{"--min-coverage=10", "--strict-coverage", r.Dir}, exitLowCoverage},
+		{[]string{"--min-coverage=6", "--strict-coverage", r.Dir}, exitOK},
+		{[]string{"--fail-under-bus-factor=2", r.Dir}, exitLowBusFactor},
+		{[]string{"--fail-under-bus-factor=1", r.Dir}, exitOK},
+		// A partial failure wins over low coverage, which wins over the bus factor
+		{[]string{"--min-coverage=10", "--strict-coverage", "--fail-under-bus-factor=2", r.Dir, missing}, exitPartialFailure},
+		{[]string{"--min-coverage=10", "--strict-coverage", "--fail-under-bus-factor=2", r.Dir}, exitLowCoverage},
+	}
+	for _, tt := range tests {
+		if res := run(t, tt.args...); res.Code != tt.want {
+			t.Errorf("gitowner %s: exit code %d, want %d\n%s", strings.Join(tt.args, " "), res.Code, tt.want, res.Stderr)
+		}
+	}
+}
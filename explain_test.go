@@ -0,0 +1,55 @@
+package main
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+var explainLine = regexp.MustCompile(`^   raw score ([0-9.]+) × bonus factor ([0-9.]+) \(repos: (\d+)\) = ([0-9.]+); commits: (\d+),`)
+
+func TestExplainBonusFactor(t *testing.T) {
+	a, b, c := testrepo.New(t), testrepo.New(t), testrepo.New(t)
+	for _, r := range []*testrepo.Repo{a, b, c} {
+		r.Commit("alice@example.com", testrepo.DaysAgo(10), nil)
+	}
+	a.Commit("bob@example.com", testrepo.DaysAgo(5), nil)
+	b.Commit("bob@example.com", testrepo.DaysAgo(5), nil)
+	c.Commit("carol@example.com", testrepo.DaysAgo(1), nil)
+
+	const rate = 0.25
+	out := mustRun(t, "--explain", "--bonus-per-repo="+strconv.FormatFloat(rate, 'g', -1, 64), a.Dir, b.Dir, c.Dir)
+	repos := make(map[int]bool)
+	for _, line := range strings.Split(out, "\n") {
+		m := explainLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		raw, _ := strconv.ParseFloat(m[1], 64)
+		factor, _ := strconv.ParseFloat(m[2], 64)
+		n, _ := strconv.Atoi(m[3])
+		score, _ := strconv.ParseFloat(m[4], 64)
+		if want := 1 + float64(n-1)*rate; math.Abs(factor-want) > 0.0005 {
+			t.Errorf("%d repositories: bonus factor %g, want %g", n, factor, want)
+		}
+		if math.Abs(score-raw*factor) > 0.01 {
+			t.Errorf("%q: score is not raw score times bonus factor", line)
+		}
+		repos[n] = true
+	}
+	if !repos[1] || !repos[2] || !repos[3] {
+		t.Errorf("want breakdowns for owners in 1, 2 and 3 repositories:\n%s", out)
+	}
+
+	out = mustRun(t, "--explain", "--explain-commits=2", a.Dir, b.Dir, c.Dir)
+	if n := strings.Count(out, " ago): "); n != 2 {
+		t.Errorf("got %d top commits, want 2:\n%s", n, out)
+	}
+	if res := run(t, "--explain-commits=2", a.Dir); res.Code != exitUsage {
+		t.Errorf("--explain-commits without --explain: exit code %d, want %d", res.Code, exitUsage)
+	}
+}
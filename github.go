@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// githubAPIURL is the GitHub REST API endpoint used by --github-resolve.
+const githubAPIURL = "https://api.github.com"
+
+// githubCacheTTL is how long a resolved (or unresolvable) email is reused
+// before GitHub is asked again.
+const githubCacheTTL = 30 * 24 * time.Hour
+
+// errGitHubRateLimited stops further lookups once the API refuses them.
+var errGitHubRateLimited = errors.New("GitHub API rate limit exceeded")
+
+// githubCacheEntry is a cached lookup; an empty Login means no account was found.
+type githubCacheEntry struct {
+	Login   string    `json:"login"`
+	Checked time.Time `json:"checked"`
+}
+
+// githubResolver maps commit emails to GitHub logins with the commit search
+// API, caching the answers under the user's cache directory
+// ($XDG_CACHE_HOME/gitowner/github-logins.json on Linux).
+type githubResolver struct {
+	client  *http.Client
+	baseURL string
+	token   string
+	now     time.Time
+	file    string
+	cache   map[string]githubCacheEntry
+	dirty   bool
+}
+
+// newGitHubResolver loads the lookup cache. A missing or unreadable cache
+// starts empty.
+func newGitHubResolver(client *http.Client, token string, now time.Time) *githubResolver {
+	r := &githubResolver{client: client, baseURL: githubAPIURL, token: token, now: now, cache: make(map[string]githubCacheEntry)}
+	if dir, err := os.UserCacheDir(); err == nil {
+		r.file = filepath.Join(dir, "gitowner", "github-logins.json")
+		if raw, err := os.ReadFile(r.file); err == nil {
+			if err := json.Unmarshal(raw, &r.cache); err != nil {
+				owner.Warnf("Warning: ignoring unreadable GitHub login cache %s", r.file)
+				r.cache = make(map[string]githubCacheEntry)
+			}
+		}
+	}
+	return r
+}
+
+// login returns the GitHub login that authored commits with email, or "" if
+// GitHub knows none.
+func (r *githubResolver) login(email string) (string, error) {
+	if entry, ok := r.cache[email]; ok && r.now.Sub(entry.Checked) < githubCacheTTL {
+		return entry.Login, nil
+	}
+
+	query := url.Values{"q": {"author-email:" + email}, "per_page": {"1"}}
+	req, err := http.NewRequest(http.MethodGet, r.baseURL+"/search/commits?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		return "", errGitHubRateLimited
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("GitHub API returned %s", resp.Status)
+	}
+
+	var result struct {
+		Items []struct {
+			Author *struct {
+				Login string `json:"login"`
+			} `json:"author"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+	login := ""
+	if len(result.Items) > 0 && result.Items[0].Author != nil {
+		login = result.Items[0].Author.Login
+	}
+	r.cache[email] = githubCacheEntry{Login: login, Checked: r.now}
+	r.dirty = true
+	return login, nil
+}
+
+// save writes the cache back if any lookup was added.
+func (r *githubResolver) save() error {
+	if !r.dirty || r.file == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(r.file), 0o755); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(r.cache)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.file, raw, 0o644)
+}
+
+// resolveGitHubLogins sets GitHubLogin on every owner: from the usernames
+// file, from a users.noreply.github.com address, or by asking GitHub. The
+// logins found are added to usernames for reviewer suggestions. Owners that
+// cannot be resolved keep just their email.
+func resolveGitHubLogins(owners []owner.OwnerScore, usernames map[string]string, r *githubResolver) {
+	for i := range owners {
+		email := owners[i].Email
+		if handle := reviewerHandle(email, usernames); handle != email {
+			owners[i].GitHubLogin = strings.TrimPrefix(handle, "@")
+			continue
+		}
+		login, err := r.login(email)
+		if errors.Is(err, errGitHubRateLimited) {
+			owner.Warnf("Warning: %v; remaining owners are shown by email (set --github-token for a higher limit).", err)
+			break
+		}
+		if err != nil {
+			owner.Warnf("Warning: could not resolve %s on GitHub: %v", email, err)
+			continue
+		}
+		if login != "" {
+			owners[i].GitHubLogin = login
+			usernames[email] = login
+		}
+	}
+	if err := r.save(); err != nil {
+		owner.Warnf("Warning: could not write GitHub login cache: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// roundTripFunc serves HTTP requests without a network.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// fakeGitHub answers commit searches from logins (email -> login, "" for no
+// match), fails for emails it does not know, and counts the requests.
+func fakeGitHub(t *testing.T, logins map[string]string, requests *int) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*requests++
+		if got := req.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization header %q", got)
+		}
+		email := strings.TrimPrefix(req.URL.Query().Get("q"), "author-email:")
+		login, ok := logins[email]
+		status, body := http.StatusOK, `{"items": []}`
+		switch {
+		case !ok:
+			status, body = http.StatusInternalServerError, ""
+		case login != "":
+			body = `{"items": [{"author": {"login": "` + login + `"}}]}`
+		}
+		return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header)}, nil
+	})}
+}
+
+func TestResolveGitHubLogins(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	defer func(w io.Writer) { owner.LogOutput = w }(owner.LogOutput)
+	owner.LogOutput = io.Discard
+
+	requests := 0
+	client := fakeGitHub(t, map[string]string{"alice@example.com": "alice-gh", "nobody@example.com": ""}, &requests)
+	owners := []owner.OwnerScore{
+		{Email: "alice@example.com"},
+		{Email: "nobody@example.com"},
+		{Email: "broken@example.com"}, // The API fails: keep the email
+		{Email: "123+octo@users.noreply.github.com"},
+		{Email: "known@example.com"},
+	}
+	usernames := map[string]string{"known@example.com": "known-gh"}
+	resolveGitHubLogins(owners, usernames, newGitHubResolver(client, "secret", testrepo.Now))
+
+	want := []string{"alice-gh", "", "", "octo", "known-gh"}
+	for i, o := range owners {
+		if o.GitHubLogin != want[i] {
+			t.Errorf("%s: got login %q, want %q", o.Email, o.GitHubLogin, want[i])
+		}
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3 (noreply and known addresses need none)", requests)
+	}
+	if usernames["alice@example.com"] != "alice-gh" {
+		t.Errorf("resolved login not added to usernames: %v", usernames)
+	}
+
+	// Successful lookups, including misses, are cached; failures are retried
+	requests = 0
+	owners = []owner.OwnerScore{{Email: "alice@example.com"}, {Email: "nobody@example.com"}, {Email: "broken@example.com"}}
+	resolveGitHubLogins(owners, map[string]string{}, newGitHubResolver(client, "secret", testrepo.Now))
+	if requests != 1 || owners[0].GitHubLogin != "alice-gh" {
+		t.Errorf("second run: got %d requests and %+v, want 1 request and alice-gh from the cache", requests, owners[0])
+	}
+}
+
+func TestGitHubRateLimitStopsLookups(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	defer func(w io.Writer) { owner.LogOutput = w }(owner.LogOutput)
+	owner.LogOutput = io.Discard
+
+	requests := 0
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{StatusCode: http.StatusForbidden, Status: "403 Forbidden", Body: io.NopCloser(strings.NewReader("")), Header: make(http.Header)}, nil
+	})}
+	owners := []owner.OwnerScore{{Email: "a@example.com"}, {Email: "b@example.com"}}
+	resolveGitHubLogins(owners, map[string]string{}, newGitHubResolver(client, "", testrepo.Now))
+	if requests != 1 || owners[0].GitHubLogin != "" || owners[1].GitHubLogin != "" {
+		t.Errorf("got %d requests and %+v, want one request and no logins", requests, owners)
+	}
+}
@@ -1,287 +1,1121 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"math"
+	"io/fs"
+	"math/rand/v2"
+	"net/http"
 	"os"
-	"sort"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
 	"strings" // Needed for string manipulation
 	"time"
 
-	"github.com/BurntSushi/toml" // Import TOML library
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/object"
-	// "golang.org/x/exp/maps" // No longer strictly necessary if not using maps.Keys
+	"github.com/mateobur/gitowner/pkg/owner"
 )
 
-// OwnerScore represents a user and their score
-type OwnerScore struct {
-	Email       string
-	Score       float64
-	RepoCount   int
-	RawScore    float64
-	AliasesUsed []string // Optional: To show which aliases were merged
+// stringList is a flag.Value that collects every occurrence of a repeatable flag.
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
 }
 
-// --- Structure for the TOML Aliases File ---
-type AliasConfig struct {
-	Aliases map[string][]string `toml:"aliases"` // canonical_email -> [alias1, alias2, ...]
+// flagWasSet reports whether the named flag was given on the command line.
+func flagWasSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
 
-// --- Function to load and process aliases ---
-func loadAliases(filePath string) (map[string]string, error) {
-	aliasMap := make(map[string]string) // Final map: alias_email -> canonical_email
-	if filePath == "" {
-		return aliasMap, nil // No file provided, return empty map
+func main() {
+	// --- Parameters ---
+	tau := flag.Float64("tau", 365.0, "Temporal decay parameter (in days)")
+	decay := flag.String("decay", owner.DecayExponential, "Recency function using --tau: exponential (exp(-age/tau)), linear (max(0, 1-age/tau)), step (1 within tau days, else 0), or none (plain commit count)")
+	count := flag.Int("count", 10, "Number of most likely owners to display")
+	bonusPerRepo := flag.Float64("bonus-per-repo", 0.1, "Multiplicative bonus factor per additional repository (e.g., 0.1 means +10% for the 2nd repo)")
+	aliasesFile := flag.String("aliases-file", "", "Optional path to a TOML file defining email aliases (e.g., aliases.toml)") // New flag
+	format := flag.String("format", "text", "Output format: text, table (aligned columns), markdown, json, yaml, csv, dot (Graphviz co-contribution graph), editor (path:owner_email:score per file), codeowners, or compact (\"email score\" lines)")
+	sampleRate := flag.Float64("sample-rate", 1.0, "Fraction of commits to score, in (0, 1]. Scores are scaled estimates with a ~95% interval")
+	splitTopLevel := flag.Bool("split-top-level", false, "Produce a separate owner ranking for every top-level directory of the HEAD tree (plus root files)")
+	identity := flag.String("identity", owner.IdentityAuthor, "Identity credited for each commit: author, committer, or both")
+	coauthorWeight := flag.Float64("coauthor-weight", 1.0, "Fraction of a commit's weight credited to each co-author named in a Co-authored-by trailer (0 ignores trailers)")
+	committerWeight := flag.Float64("committer-weight", 0.5, "With --identity=both, fraction of a commit's weight credited to a committer who is not the author")
+	ticketBonus := flag.Float64("ticket-bonus", 0, "Boost per distinct ticket referenced in a commit message (e.g., 0.05 means +5% per ticket); 0 disables")
+	ticketRegex := flag.String("ticket-regex", owner.DefaultTicketRegex, "Regular expression matching ticket references in commit messages")
+	explain := flag.Bool("explain", false, "Show a score breakdown under each owner (text format): raw score, bonus factor, repositories and commits")
+	explainCommits := flag.Int("explain-commits", 0, "With --explain, also list the top owner's N highest-weighted commits with their age")
+	dedupAcrossRepos := flag.Bool("dedup-across-repos", false, "Score each unique commit hash once even when it appears in several of the given repositories")
+	discountReverts := flag.Bool("discount-reverts", false, "Discount revert commits and commits whose net effect was reverted (reinstated commits keep full weight)")
+	revertWeight := flag.Float64("revert-weight", 0, "With --discount-reverts, weight multiplier applied to discounted commits (0 drops them)")
+	seed := flag.Uint64("seed", 0, "Seed for all probabilistic behavior such as --sample-rate (random when unset; the seed used is printed)")
+	maintenanceBonus := flag.Float64("maintenance-bonus", 0, "Boost per year of average age of the files a commit touches, rewarding maintenance of older code (e.g., 0.1 = +10% per year); 0 disables")
+	strict := flag.Bool("strict", false, "Skip a repository entirely if its history cannot be walked completely, instead of keeping partial results, and exit with code 4 when no commit data is found")
+	reposFrom := flag.String("repos-from", "", "Also analyze the repository paths or URLs listed in this file (one per line, '#' comments, '-' for stdin); a '-' argument reads stdin too")
+	filesFrom := flag.String("files-from", "", "Only score commits touching the paths listed in this file (one per line, '-' for stdin), e.g. the files changed by a pull request")
+	suggestReviewers := flag.Bool("suggest-reviewers", false, "Print a ready-to-post reviewer-suggestion message for the top --count owners instead of the ranking")
+	suggestTemplate := flag.String("suggest-template", defaultSuggestTemplate, "Template for --suggest-reviewers; placeholders: {reviewers}, {reviewer1}, {reviewer2}, ..., {count}")
+	githubResolve := flag.Bool("github-resolve", false, "Annotate the top owners with their GitHub @login, looked up by commit email with the GitHub API (cached; owners that cannot be resolved keep their email)")
+	githubToken := flag.String("github-token", os.Getenv("GITHUB_TOKEN"), "GitHub API token for --github-resolve (default $GITHUB_TOKEN); raises the rate limit")
+	usernamesFile := flag.String("usernames-file", "", "Optional TOML file with a [usernames] table mapping emails to handles for --suggest-reviewers and --format=codeowners")
+	weightBy := flag.String("weight-by", owner.WeightByCommits, "Unit of ownership: commits, active-days (distinct calendar days with commits, each decayed by recency), or regions (contiguous blamed regions of the HEAD tree; implies --full-blame)")
+	fullBlame := flag.Bool("full-blame", false, "Score the current HEAD tree by blaming every file: each line credits its last author, decayed by the line's age (slow)")
+	noBlameCache := flag.Bool("no-blame-cache", false, "Blame every file again instead of reusing cached results for unchanged files (--full-blame)")
+	cacheDir := flag.String("cache-dir", "", "Cache each repository's commit walk in this directory and reuse it while HEAD and the scoring options are unchanged (only with exponential decay or none, without --weight-floor, are results reused across times)")
+	blameWorkers := flag.Int("blame-workers", runtime.NumCPU(), "Number of files blamed concurrently with --full-blame")
+	anonymize := flag.Bool("anonymize", false, "Replace every email in the report with a stable token (leading hex digits of SHA-256 over --salt plus the canonical email) and omit names and aliases; scores and rankings are unchanged")
+	salt := flag.String("salt", "", "Salt for --anonymize tokens; without a secret salt, anyone can recompute the token of a known email")
+	relativeTo := flag.String("relative-to", "", "Express every score as a ratio of this contributor's score (the baseline shows 1.00)")
+	releaseBonus := flag.Float64("release-proximity-bonus", 0, "Boost for commits made within --release-window-days of a tagged commit (e.g., 0.2 = +20%); 0 disables")
+	releaseWindowDays := flag.Float64("release-window-days", 14, "Window in days before or after a tagged commit for --release-proximity-bonus")
+	originBonus := flag.Float64("origin-bonus", 0, "Undecayed weight added to the author of each repository's earliest scored commit (the root commit, or the first commit in scope with --path), so originators keep ownership when inactive; 0 disables")
+	signedBonus := flag.Float64("signed-bonus", 0, "Boost for commits whose PGP signature verifies against --keyring (e.g., 0.2 = +20%); 0 disables")
+	signedOnly := flag.Bool("signed-only", false, "Score only commits carrying a PGP signature; unsigned commits are dropped (listed with --verbose)")
+	verifySignatures := flag.Bool("verify-signatures", false, "With --signed-only, also drop commits whose signature does not verify against --keyring")
+	keyringFile := flag.String("keyring", "", "ASCII-armored OpenPGP public keyring used to verify commit signatures")
+	topKPrecise := flag.Int("top-k-precise", 0, "Limit memory on huge histories: a cheap first pass picks the K authors with the most commits and only they are scored precisely (0 disables)")
+	sortBy := flag.String("sort", owner.SortScore, "Primary ranking key, applied before the --count cut: score, repos (most repositories), recent (most recent commit) or email; ties fall back to score, repos, then email")
+	minScore := flag.Float64("min-score", 0, "Drop owners whose final score (including the per-repo bonus, before --relative-to) is below this. Applied after scoring, before the --count cut")
+	minRepos := flag.Int("min-repos", 0, "Drop owners who contributed to fewer than this many repositories. Applied after scoring, before the --count cut")
+	pruneStale := flag.String("prune-stale", "", "Drop owners whose most recent commit is older than this (e.g., 730d, 2y, 6mo), before the --count cut")
+	diffSpec := flag.String("diff", "", "Score the historical owners of the files changed between two refs, given as base..branch (e.g., main..feature)")
+	codeownersPrior := flag.String("codeowners-prior", "", "Existing CODEOWNERS file for --format=codeowners: keep its owner for a file unless the new top owner clearly dominates")
+	mailmapFile := flag.String("mailmap", "", "Git .mailmap file merging author identities, combined with --aliases-file (default: each repository's .mailmap, if present)")
+	noMailmap := flag.Bool("no-mailmap", false, "Ignore the repositories' .mailmap files")
+	codeownersDepth := flag.Int("codeowners-depth", 1, "With --format=codeowners, assign owners per directory down to this many levels (1 = one owner per top-level directory, 0 = per file)")
+	codeownersMargin := flag.Float64("codeowners-margin", defaultCodeownersMargin, "With --codeowners-prior, keep the listed owner while their score is within this fraction of the top owner's")
+	depsFile := flag.String("deps-file", "", "TOML file mapping each file to the files that import it ([dependents] table, generated externally); changed files weigh 1 + ln(dependents)")
+	remove := flag.String("remove", "", "Simulate removing this contributor: report repos, top-level directories and files that would be orphaned or get a new top owner")
+	orphanThreshold := flag.Float64("orphan-threshold", defaultOrphanThreshold, "With --remove, an area is orphaned when no remaining owner's score reaches this; with --find-orphans, contributors below it are ignored")
+	configFile := flag.String("config", "", "TOML file setting any flag by name (e.g., tau = 180, exclude-email = [\"ci@example.com\"]) plus an optional [aliases] table; flags given on the command line override it")
+	progress := flag.Bool("progress", false, "Show commits walked and repositories done on stderr while scanning (only when stderr is a terminal)")
+	quiet := flag.Bool("quiet", false, "Print nothing on stderr but errors; the report still goes to stdout")
+	verbose := flag.Bool("verbose", false, "Also print a debug line on stderr for every scored commit")
+	outputPath := flag.String("output", "", "Write the report to this file (created or truncated) instead of stdout; \"-\" means stdout. Diagnostics still go to stderr")
+	sqliteOut := flag.String("sqlite-out", "", "Also write owners, per-commit credits and per-file owners to this SQLite database (replaced if it exists)")
+	parametersOut := flag.String("parameters-out", "", "Also write the run parameters to this file as a JSON object (replaced if it exists), e.g. next to --format=csv")
+	allBranches := flag.Bool("all-branches", false, "Score commits reachable from any local or remote-tracking branch, not just HEAD (each commit is visited once)")
+	followRenames := flag.Bool("follow-renames", false, "Credit commits made to a file before it was renamed to its current path, for --path, --ignore-paths, --ext and per-file reports")
+	renameThreshold := flag.Int("rename-threshold", 50, "Minimum similarity, in percent, for --follow-renames to treat a deleted and an added file as a rename")
+	allRefs := flag.Bool("all-refs", false, "Like --all-branches, but also score history reachable only from tags")
+	releasedOnly := flag.Bool("released-only", false, "Score only commits reachable from a tag, ignoring unreleased work (repos without tags fall back to all history)")
+	matrix := flag.Bool("matrix", false, "Print an ownership matrix: repository -> directory tree -> top owners (text or markdown)")
+	matrixDepth := flag.Int("matrix-depth", 1, "Directory levels shown below each repository with --matrix")
+	matrixBreadth := flag.Int("matrix-breadth", 0, "Maximum directories shown per node with --matrix, heaviest first (0 = all)")
+	matrixOwners := flag.Int("matrix-owners", 1, "Top owners listed per node with --matrix")
+	timeout := flag.Duration("timeout", 0, "Stop scanning after this long (e.g., 30s, 5m) and print the results gathered so far; 0 means no limit. Ctrl-C does the same")
+	perRepo := flag.Bool("per-repo", false, "After the overall ranking, print a top --count ranking for each repository (text or markdown)")
+	topFilesMode := flag.Bool("top-files", false, "Rank the --count files with the most decayed activity and show each one's dominant owner (text, markdown, json or csv)")
+	recencyMode := flag.Bool("recency-histogram", false, "Show how many commits each of the top --count owners made in each --recency-buckets age range (text, markdown, json or csv)")
+	recencyBuckets := flag.String("recency-buckets", "30d,90d,365d", "Increasing, comma-separated upper bounds of the --recency-histogram age ranges; commits older than the last one form a final range")
+	busFactorMode := flag.Bool("bus-factor", false, "Report the bus factor overall and per repository: the fewest contributors holding more than --bus-threshold of the score (text, markdown or json)")
+	busThreshold := flag.Float64("bus-threshold", defaultBusThreshold, "Share of the total score, in (0, 1), that the contributors counted by --bus-factor must exceed")
+	findOrphansMode := flag.Bool("find-orphans", false, "List repos, top-level directories and files whose contributors above --orphan-threshold were all last active before the --prune-stale cutoff")
+	weightByLines := flag.Bool("weight-by-lines", false, "Multiply each commit's weight by the number of lines it added plus deleted (diffs every commit, so slower); merge commits count as 1")
+	weightFloor := flag.Float64("weight-floor", 0, "Minimum recency factor, in [0, 1], of any in-scope commit regardless of age, so foundational authors keep a baseline presence (0 = pure decay)")
+	var files stringList
+	var ignorePaths stringList
+	var extensions stringList
+	flag.Var(&extensions, "ext", "Only count files with this extension, e.g. go or .tsx (repeatable); commits touching no such file are dropped")
+	extScale := flag.Bool("ext-scale", false, "With --ext, scale each commit by the fraction of its changed files that match")
+	flag.Var(&ignorePaths, "ignore-paths", "Files that never count, as a gitignore-style pattern such as vendor/** or *.lock (repeatable); commits touching only such files are dropped")
+	flag.Var(&ignorePaths, "exclude-path", "Same as --ignore-paths, e.g. --exclude-path=gen/ to score everything outside the gen directory (repeatable; applied after --path)")
+	rawCount := flag.Bool("raw-count", false, "Rank by plain commit counts: every commit weighs 1 (no decay or other factors) and the cross-repository bonus is 0 unless --bonus-per-repo is given")
+	noDetectDotGit := flag.Bool("no-detect-dotgit", false, "Analyze each path exactly as given instead of walking up from a subdirectory to the top level of its repository")
+	var repoWeightSpecs stringList
+	flag.Var(&repoWeightSpecs, "repo-weight", "Multiply every commit's weight in the repositories matching a path or URL pattern, e.g. services/core=2 (repeatable; the longest matching pattern wins; default 1)")
+	var subtrees stringList
+	flag.Var(&subtrees, "path", "Only score commits touching files under this repository-relative directory, e.g. services/billing/ (repeatable)")
+	flag.Var(&files, "file", "Only score commits touching this repository-relative path (repeatable); with --format=editor, print its owner")
+	var excludeDateRanges stringList
+	recursive := flag.Bool("recursive", false, "Treat directory arguments as trees to search for Git repositories (descent stops at each repository found)")
+	maxDepth := flag.Int("max-depth", 0, "With --recursive, search at most this many directory levels below each argument (0 = unlimited)")
+	cloneDepth := flag.Int("depth", 0, "For repository URLs, clone only this many commits per branch (0 = full history)")
+	cloneDir := flag.String("clone-dir", "", "For repository URLs, keep clones in this directory and update them on later runs (default: a temporary directory removed at exit)")
+	suggestAliasesMode := flag.Bool("suggest-aliases", false, "Print a TOML [aliases] table of owners that look like the same person (same local part at another domain, same full name, or nearly identical address) instead of the ranking; nothing is merged")
+	aliasMaxDistance := flag.Int("alias-max-distance", 1, "With --suggest-aliases, largest number of edits between two addresses that still look alike (0 disables the check)")
+	aliasMinLocal := flag.Int("alias-min-local", 4, "With --suggest-aliases, shortest local part compared by local part or edit distance, so short shared names like dev@ are not matched")
+	mergeByName := flag.Bool("merge-by-name", false, "Merge identities whose most used author names match (ignoring case and spacing) into the most used email of each group, after explicit aliases and mailmaps. Common names can over-merge, so check the aliases listed")
+	normalizeGmail := flag.Bool("normalize-gmail", false, "Treat addresses that differ only in dots or a +tag in the local part as one person for --normalize-domains (e.g., john.doe+work@gmail.com = johndoe@gmail.com). Explicit aliases still win")
+	normalizeDomainsFlag := flag.String("normalize-domains", strings.Join(owner.DefaultNormalizeDomains, ","), "Comma-separated email domains normalized by --normalize-gmail")
+	excludeBots := flag.Bool("exclude-bots", false, "Drop commits by well-known bot and CI accounts (emails or names containing [bot], noreply@/ci@/jenkins@ addresses, dependabot, renovate, github-actions, ...)")
+	var excludeEmails, excludeEmailRegexes, excludeMessageRegexes stringList
+	flag.Var(&excludeEmails, "exclude-email", "Drop commits by this author email, after alias resolution (repeatable)")
+	flag.Var(&excludeEmailRegexes, "exclude-email-regex", "Drop commits whose author email or name matches this case-insensitive regular expression (repeatable)")
+	flag.Var(&excludeMessageRegexes, "exclude-message-regex", "Drop commits whose message matches this regular expression, e.g. '^chore: bump' (repeatable; case-insensitive unless it starts with (?-i))")
+	ref := flag.String("ref", "", "Score the history of this branch, tag or commit hash instead of HEAD")
+	compare := flag.Bool("compare", false, "Compare each owner's score in the last --window-b against the --window-a before it and report the change, biggest movers first (up to --count)")
+	windowA := flag.String("window-a", "30d", "Length of the earlier --compare window, which ends where --window-b starts (e.g., 30d, 2w, 6mo)")
+	windowB := flag.String("window-b", "30d", "Length of the recent --compare window, which ends now (e.g., 30d, 2w, 6mo)")
+	nowFlag := flag.String("now", "", "Reference time that commit ages, relative dates and ages in other flags are measured from (YYYY-MM-DD or RFC3339; default: the current time), for reproducible reports")
+	flag.StringVar(nowFlag, "as-of", "", "Same as --now")
+	maxFutureSkew := flag.String("max-future-skew", "1d", "Warn about commits dated more than this after the reference time (a wrong clock), e.g. 1d or 12h; they count as brand new. 0 disables the check")
+	dropFutureCommits := flag.Bool("drop-future-commits", false, "Drop the commits caught by --max-future-skew instead of counting them as brand new")
+	since := flag.String("since", "", "Only score commits authored at or after this time (YYYY-MM-DD, RFC3339, now/today/yesterday, or an age like 90d, 2w, 6mo, 1y)")
+	until := flag.String("until", "", "Only score commits authored before this time; a plain date includes that whole day (same syntax as --since)")
+	flag.Var(&excludeDateRanges, "exclude-date-range", "Drop commits authored within <start>..<end> (YYYY-MM-DD, RFC3339, now/today/yesterday, or an age like 90d; end day inclusive). Repeatable")
+	minCoverage := flag.Int("min-coverage", 0, "Warn that the ranking may be unreliable when fewer than this many commits are scored after filtering")
+	strictCoverage := flag.Bool("strict-coverage", false, "Exit with code 5 (after printing results) when fewer than --min-coverage commits are scored")
+	reportInvalidEmails := flag.Bool("report-invalid-emails", false, "List malformed author emails (per net/mail) and their commit counts on stderr")
+	bucketInvalidEmails := flag.Bool("bucket-invalid-emails", false, "Credit all malformed emails to a single \"(invalid)\" owner")
+	excludeInvalidEmails := flag.Bool("exclude-invalid-emails", false, "Drop commits credited to malformed emails")
+	errorOnEmpty := flag.Bool("error-on-empty", false, "Exit with code 4 when no commit data is found (implied by --strict)")
+	jsonEnvelope := flag.Bool("json-envelope", false, "With --format=json, print an object with the run parameters under \"parameters\", the ranking under \"owners\" and the skipped repositories and their errors under \"skipped\", instead of a bare array")
+	failUnderBusFactor := flag.Int("fail-under-bus-factor", 0, "Exit with code 6 (after printing results) when the overall bus factor, counted with --bus-threshold, is below this (0 disables)")
+	flag.Usage = usage
+	// Parse errors exit with exitUsage rather than the flag package's default of 2
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(exitOK)
+		}
+		os.Exit(exitUsage)
+	}
+	var config *Config
+	if *configFile != "" {
+		var err error
+		if config, err = loadConfig(*configFile); err != nil {
+			exitf(exitUsage, "Error loading config: %v", err)
+		}
+		if err := config.apply(flag.CommandLine); err != nil {
+			exitf(exitUsage, "Error in config file %s: %v", *configFile, err)
+		}
+		// The [aliases] table stands in for an aliases file unless one is given
+		if *aliasesFile == "" && len(config.Aliases) > 0 {
+			*aliasesFile = *configFile
+		}
 	}
 
-	fmt.Printf("Attempting to load aliases from: %s\n", filePath)
-	data, err := os.ReadFile(filePath)
+	// --- Input Validation ---
+	repoPaths, err := collectRepoArgs(flag.Args(), *reposFrom, *filesFrom == "-")
 	if err != nil {
-		// If the file doesn't exist, it's not necessarily a fatal error if the flag was optional
-		if os.IsNotExist(err) {
-			fmt.Printf("Warning: Alias file not found at %s, proceeding without aliases.\n", filePath)
-			return aliasMap, nil // Return empty map, not an execution error
-		}
-		return nil, fmt.Errorf("failed to read alias file %s: %w", filePath, err)
-	}
-
-	var config AliasConfig
-	if _, err := toml.Decode(string(data), &config); err != nil {
-		return nil, fmt.Errorf("failed to parse alias file %s: %w", filePath, err)
-	}
-
-	// Invert the map for quick lookup: alias -> canonical
-	duplicates := make(map[string]string) // To detect if an alias points to multiple canonicals
-	for canonical, aliasList := range config.Aliases {
-		canonical = strings.ToLower(strings.TrimSpace(canonical)) // Normalize canonical
-		if canonical == "" {
-			continue
-		} // Ignore empty entries
-
-		// Ensure the canonical is not already an alias for another
-		if existingCanonical, isAlias := aliasMap[canonical]; isAlias {
-			fmt.Fprintf(os.Stderr, "Warning: Canonical email '%s' is already listed as an alias for '%s'. Check your aliases file.\n", canonical, existingCanonical)
-			// Decide how to handle this, here we just ignore it as canonical if it's already an alias.
-			continue
-		}
-
-		for _, alias := range aliasList {
-			alias = strings.ToLower(strings.TrimSpace(alias)) // Normalize alias
-			if alias == "" || alias == canonical {
-				continue
-			} // Ignore empty aliases or those identical to the canonical
-
-			if existingCanonical, exists := aliasMap[alias]; exists {
-				// This alias was already mapped to another canonical!
-				if existingCanonical != canonical {
-					fmt.Fprintf(os.Stderr, "Warning: Alias '%s' is mapped to multiple canonical emails ('%s' and '%s'). Using '%s'. Check your aliases file.\n", alias, existingCanonical, canonical, canonical)
-					// We could decide to keep the first, the last, or error out. Here we overwrite (last one wins).
-				}
-				duplicates[alias] = canonical // Register the conflict (last one wins)
-			}
-			// Check if an email listed as an alias is also listed as a canonical email itself
-			if _, isAlsoCanonical := config.Aliases[alias]; isAlsoCanonical {
-				fmt.Fprintf(os.Stderr, "Warning: Email '%s' is listed both as an alias (for '%s') and as a canonical email itself. Using it as an alias.\n", alias, canonical)
-			}
-			aliasMap[alias] = canonical
+		exitf(exitUsage, "Error: %v", err)
+	}
+	if len(repoPaths) == 0 {
+		flag.Usage()
+		os.Exit(exitUsage)
+	}
+	switch {
+	case *quiet && *verbose:
+		exitf(exitUsage, "Error: --quiet and --verbose are mutually exclusive.")
+	case *quiet:
+		owner.LogLevel = owner.LevelError
+	case *verbose:
+		owner.LogLevel = owner.LevelDebug
+	}
+	if *maxDepth < 0 {
+		exitf(exitUsage, "Error: --max-depth cannot be negative.")
+	}
+	if *recursive {
+		repoPaths = expandRecursive(repoPaths, *maxDepth)
+		if len(repoPaths) == 0 {
+			exitf(exitUsage, "Error: no Git repositories found.")
 		}
+		owner.Infof("Found %d repositories.", len(repoPaths))
 	}
-	// Apply detected duplicates (last one wins)
-	for alias, canonical := range duplicates {
-		aliasMap[alias] = canonical
+	if !*noDetectDotGit {
+		// Resolved here too, so per-repository settings are keyed like the scan
+		repoPaths = owner.ResolveRepoRoots(repoPaths)
 	}
-
-	fmt.Printf("Loaded %d alias mappings.\n", len(aliasMap))
-	return aliasMap, nil
-}
-
-// --- Function to get the canonical email ---
-func getCanonicalEmail(email string, aliasMap map[string]string) string {
-	normalizedEmail := strings.ToLower(strings.TrimSpace(email))
-	if canonical, ok := aliasMap[normalizedEmail]; ok {
-		return canonical // Returns the mapped canonical email
+	if *bonusPerRepo < 0 {
+		exitf(exitUsage, "Error: --bonus-per-repo cannot be negative.")
 	}
-	return normalizedEmail // Returns the original (normalized) email if it's not an alias
-}
-
-// processRepoCommits analyzes a single repository and updates the global maps.
-// Returns an error if it cannot process the repository.
-func processRepoCommits(repoPath string, tau float64, aliasMap map[string]string, userScores map[string]float64, userRepos map[string]map[string]struct{}, userAliases map[string]map[string]struct{}) error { // Added userAliases
-	fmt.Printf("Processing repository: %s\n", repoPath)
-	repo, err := git.PlainOpen(repoPath)
+	if *sampleRate <= 0 || *sampleRate > 1 {
+		exitf(exitUsage, "Error: --sample-rate must be in the range (0, 1].")
+	}
+	if err := owner.ValidDecay(*decay); err != nil {
+		exitf(exitUsage, "Error: --decay: %v", err)
+	}
+	switch *identity {
+	case owner.IdentityAuthor, owner.IdentityCommitter, owner.IdentityBoth:
+	default:
+		exitf(exitUsage, "Error: unknown --identity %q (expected author, committer, or both).", *identity)
+	}
+	switch *weightBy {
+	case owner.WeightByCommits, owner.WeightByActiveDays:
+	case owner.WeightByRegions:
+		*fullBlame = true // Regions come from blaming the HEAD tree
+	default:
+		exitf(exitUsage, "Error: unknown --weight-by %q (expected commits, active-days, or regions).", *weightBy)
+	}
+	if *weightBy == owner.WeightByActiveDays && *fullBlame {
+		exitf(exitUsage, "Error: --weight-by=%s cannot be combined with --full-blame.", *weightBy)
+	}
+	if *weightByLines && *fullBlame {
+		exitf(exitUsage, "Error: --weight-by-lines cannot be combined with --full-blame or --weight-by=regions, which already count lines.")
+	}
+	if *bucketInvalidEmails && *excludeInvalidEmails {
+		exitf(exitUsage, "Error: --bucket-invalid-emails and --exclude-invalid-emails are mutually exclusive.")
+	}
+	invalidEmails := owner.InvalidEmailsKeep
+	if *bucketInvalidEmails {
+		invalidEmails = owner.InvalidEmailsBucket
+	} else if *excludeInvalidEmails {
+		invalidEmails = owner.InvalidEmailsExclude
+	}
+	if *remove != "" && (*splitTopLevel || *fullBlame || *suggestReviewers || *format != "text") {
+		exitf(exitUsage, "Error: --remove only supports the default text output of a commit walk.")
+	}
+	if *matrix && (*splitTopLevel || *suggestReviewers || (*format != "text" && *format != "markdown" && *format != "json")) {
+		exitf(exitUsage, "Error: --matrix only supports --format=text, markdown or json.")
+	}
+	if *matrixDepth < 1 || *matrixBreadth < 0 || *matrixOwners < 1 {
+		exitf(exitUsage, "Error: --matrix-depth and --matrix-owners must be at least 1, --matrix-breadth non-negative.")
+	}
+	if *findOrphansMode && (*remove != "" || *matrix || *splitTopLevel || *suggestReviewers || *fullBlame || *format != "text") {
+		exitf(exitUsage, "Error: --find-orphans only supports the default text output of a commit walk.")
+	}
+	if *busFactorMode && (*remove != "" || *matrix || *findOrphansMode || *splitTopLevel || *suggestReviewers || (*format != "text" && *format != "markdown" && *format != "json")) {
+		exitf(exitUsage, "Error: --bus-factor cannot be combined with other report modes and only supports --format=text, markdown or json.")
+	}
+	if *topFilesMode && (*remove != "" || *matrix || *findOrphansMode || *busFactorMode || *splitTopLevel || *suggestReviewers || *perRepo || *compare || (*format != "text" && *format != "markdown" && *format != "json" && *format != "csv")) {
+		exitf(exitUsage, "Error: --top-files cannot be combined with other report modes and supports --format=text, markdown, json or csv.")
+	}
+	if *recencyMode && (*remove != "" || *matrix || *findOrphansMode || *busFactorMode || *topFilesMode || *splitTopLevel || *suggestReviewers || *perRepo || *compare || *fullBlame || (*format != "text" && *format != "markdown" && *format != "json" && *format != "csv")) {
+		exitf(exitUsage, "Error: --recency-histogram cannot be combined with other report modes or --full-blame, and supports --format=text, markdown, json or csv.")
+	}
+	ageBuckets, err := parseAgeBuckets(*recencyBuckets)
 	if err != nil {
-		return fmt.Errorf("failed to open repository %s: %w", repoPath, err)
+		exitf(exitUsage, "Error: invalid --recency-buckets: %v", err)
+	}
+	if *perRepo && (*remove != "" || *matrix || *findOrphansMode || *busFactorMode || *splitTopLevel || *suggestReviewers || (*format != "text" && *format != "markdown")) {
+		exitf(exitUsage, "Error: --per-repo only supports the plain ranking with --format=text or markdown.")
+	}
+	if *suggestAliasesMode && (*remove != "" || *matrix || *findOrphansMode || *busFactorMode || *topFilesMode || *splitTopLevel || *compare || *suggestReviewers || *perRepo || *anonymize || *format != "text") {
+		exitf(exitUsage, "Error: --suggest-aliases cannot be combined with other report modes, --anonymize or --format.")
+	}
+	if *aliasMaxDistance < 0 || *aliasMinLocal < 0 {
+		exitf(exitUsage, "Error: --alias-max-distance and --alias-min-local cannot be negative.")
+	}
+	if *anonymize && (*remove != "" || *compare || *splitTopLevel || *suggestReviewers || *githubResolve || *format == "codeowners") {
+		exitf(exitUsage, "Error: --anonymize cannot be combined with --remove, --compare, --split-top-level, --suggest-reviewers, --github-resolve or --format=codeowners.")
+	}
+	if *jsonEnvelope && (*format != "json" || *remove != "" || *matrix || *findOrphansMode || *busFactorMode || *topFilesMode || *splitTopLevel || *compare || *suggestReviewers) {
+		exitf(exitUsage, "Error: --json-envelope only applies to the plain ranking with --format=json.")
+	}
+	if *parametersOut != "" && (*remove != "" || *matrix || *findOrphansMode || *busFactorMode || *topFilesMode || *splitTopLevel || *compare || *suggestReviewers) {
+		exitf(exitUsage, "Error: --parameters-out only applies to the plain ranking.")
+	}
+	if *rawCount {
+		if *fullBlame || *originBonus > 0 || *weightBy != owner.WeightByCommits || (flagWasSet("decay") && *decay != owner.DecayNone) {
+			exitf(exitUsage, "Error: --raw-count cannot be combined with --full-blame, --origin-bonus, or a --weight-by or --decay other than commits and none.")
+		}
+		*decay = owner.DecayNone
+		if !flagWasSet("bonus-per-repo") {
+			*bonusPerRepo = 0
+		}
+	}
+	repoWeightPatterns, err := parseRepoWeights(repoWeightSpecs)
+	if err != nil {
+		exitf(exitUsage, "Error: invalid --repo-weight: %v", err)
+	}
+	if *salt != "" && !*anonymize {
+		exitf(exitUsage, "Error: --salt requires --anonymize.")
+	}
+	if err := owner.ValidSort(*sortBy); err != nil {
+		exitf(exitUsage, "Error: invalid --sort: %v", err)
+	}
+	if *minScore < 0 || *minRepos < 0 {
+		exitf(exitUsage, "Error: --min-score and --min-repos must not be negative.")
+	}
+	if *failUnderBusFactor < 0 {
+		exitf(exitUsage, "Error: --fail-under-bus-factor cannot be negative.")
+	}
+	if *failUnderBusFactor > 0 && (*remove != "" || *compare || *splitTopLevel || *findOrphansMode) {
+		exitf(exitUsage, "Error: --fail-under-bus-factor cannot be combined with --remove, --compare, --split-top-level or --find-orphans.")
+	}
+	if *timeout < 0 {
+		exitf(exitUsage, "Error: --timeout must not be negative, got %v.", *timeout)
+	}
+	if *busThreshold <= 0 || *busThreshold >= 1 {
+		exitf(exitUsage, "Error: --bus-threshold must be in the range (0, 1).")
+	}
+	if *findOrphansMode && *pruneStale == "" {
+		exitf(exitUsage, "Error: --find-orphans requires --prune-stale to set the inactivity cutoff.")
+	}
+	if *weightFloor < 0 || *weightFloor > 1 {
+		exitf(exitUsage, "Error: --weight-floor must be in the range [0, 1].")
+	}
+	if *minCoverage < 0 {
+		exitf(exitUsage, "Error: --min-coverage cannot be negative.")
+	}
+	if *strictCoverage && *minCoverage == 0 {
+		exitf(exitUsage, "Error: --strict-coverage requires --min-coverage.")
+	}
+	if *coauthorWeight < 0 {
+		exitf(exitUsage, "Error: --coauthor-weight cannot be negative.")
+	}
+	if *committerWeight < 0 {
+		exitf(exitUsage, "Error: --committer-weight cannot be negative.")
+	}
+	switch *format {
+	case "text", "table", "markdown", "json", "yaml", "csv", "dot", "editor", "codeowners", "compact":
+	default:
+		exitf(exitUsage, "Error: unknown --format %q (expected text, table, markdown, json, yaml, csv, dot, editor, codeowners, or compact).", *format)
+	}
+	if *format == "codeowners" && len(repoPaths) != 1 {
+		exitf(exitUsage, "Error: --format=codeowners describes a single repository; pass exactly one.")
+	}
+	if *codeownersPrior != "" && *format != "codeowners" {
+		exitf(exitUsage, "Error: --codeowners-prior requires --format=codeowners.")
+	}
+	if *cloneDepth < 0 {
+		exitf(exitUsage, "Error: --depth cannot be negative.")
+	}
+	if *allRefs {
+		*allBranches = true
+	}
+	if *ref != "" && (*allBranches || *releasedOnly) {
+		exitf(exitUsage, "Error: --ref cannot be combined with --all-branches, --all-refs or --released-only.")
+	}
+	if *mailmapFile != "" && *noMailmap {
+		exitf(exitUsage, "Error: --mailmap and --no-mailmap are mutually exclusive.")
+	}
+	if *codeownersDepth < 0 {
+		exitf(exitUsage, "Error: --codeowners-depth cannot be negative.")
+	}
+	if flagWasSet("codeowners-depth") && *format != "codeowners" {
+		exitf(exitUsage, "Error: --codeowners-depth requires --format=codeowners.")
+	}
+	if *codeownersMargin < 0 || *codeownersMargin >= 1 {
+		exitf(exitUsage, "Error: --codeowners-margin must be in the range [0, 1).")
 	}
 
-	ref, err := repo.Head()
+	if *revertWeight < 0 || *revertWeight > 1 {
+		exitf(exitUsage, "Error: --revert-weight must be between 0 and 1.")
+	}
+	if *maintenanceBonus < 0 {
+		exitf(exitUsage, "Error: --maintenance-bonus cannot be negative.")
+	}
+	if *cacheDir != "" && (*dedupAcrossRepos || *weightBy == owner.WeightByActiveDays || *sqliteOut != "" || *originBonus > 0) {
+		owner.Warnf("Warning: --cache-dir has no effect with --dedup-across-repos, --weight-by=active-days, --sqlite-out or --origin-bonus.")
+	}
+	if *originBonus < 0 {
+		exitf(exitUsage, "Error: --origin-bonus cannot be negative.")
+	}
+	if *originBonus > 0 && *fullBlame {
+		exitf(exitUsage, "Error: --origin-bonus cannot be combined with --full-blame or --weight-by=regions.")
+	}
+	if *blameWorkers < 1 {
+		exitf(exitUsage, "Error: --blame-workers must be at least 1.")
+	}
+	if *releaseBonus < 0 || *releaseWindowDays < 0 {
+		exitf(exitUsage, "Error: --release-proximity-bonus and --release-window-days cannot be negative.")
+	}
+	if *signedBonus < 0 {
+		exitf(exitUsage, "Error: --signed-bonus cannot be negative.")
+	}
+	if *signedBonus > 0 && *keyringFile == "" {
+		exitf(exitUsage, "Error: --signed-bonus requires --keyring.")
+	}
+	if *verifySignatures && (!*signedOnly || *keyringFile == "") {
+		exitf(exitUsage, "Error: --verify-signatures requires --signed-only and --keyring.")
+	}
+	if *explainCommits < 0 {
+		exitf(exitUsage, "Error: --explain-commits cannot be negative.")
+	}
+	if *explainCommits > 0 && (!*explain || *fullBlame || *format != "text") {
+		exitf(exitUsage, "Error: --explain-commits requires --explain with the default text output of a commit walk.")
+	}
+	if len(excludeMessageRegexes) > 0 && *fullBlame {
+		exitf(exitUsage, "Error: --exclude-message-regex cannot be combined with --full-blame or --weight-by=regions.")
+	}
+	if *renameThreshold < 1 || *renameThreshold > 100 {
+		exitf(exitUsage, "Error: --rename-threshold must be between 1 and 100.")
+	}
+	if *followRenames && *fullBlame {
+		exitf(exitUsage, "Error: --follow-renames cannot be combined with --full-blame or --weight-by=regions, which already score current paths.")
+	}
+	if *signedOnly && *fullBlame {
+		exitf(exitUsage, "Error: --signed-only cannot be combined with --full-blame or --weight-by=regions.")
+	}
+	if *topKPrecise < 0 {
+		exitf(exitUsage, "Error: --top-k-precise cannot be negative.")
+	}
+	if *ticketBonus < 0 {
+		exitf(exitUsage, "Error: --ticket-bonus cannot be negative.")
+	}
+	ticketPattern, err := regexp.Compile(*ticketRegex)
 	if err != nil {
-		// Could be an empty repo or one without commits
-		return fmt.Errorf("failed to get HEAD for repository %s: %w", repoPath, err)
+		exitf(exitUsage, "Error: invalid --ticket-regex: %v", err)
+	}
+	if *ticketBonus == 0 && !*explain {
+		ticketPattern = nil // Skip message parsing when nothing uses the counts
 	}
 
-	commitIter, err := repo.Log(&git.LogOptions{From: ref.Hash()})
+	if *splitTopLevel && (*filesFrom != "" || *diffSpec != "" || len(subtrees) > 0 || *suggestReviewers) {
+		exitf(exitUsage, "Error: --split-top-level cannot be combined with --files-from, --diff, --path, or --suggest-reviewers.")
+	}
+	if *splitTopLevel && *format != "text" && *format != "markdown" {
+		exitf(exitUsage, "Error: --split-top-level does not support --format=%s.", *format)
+	}
+
+	// Every relative date or age in the flags is resolved against this one instant
+	now := time.Now().UTC()
+	if *nowFlag != "" {
+		if now, err = owner.ParseDateBound(*nowFlag, now, false); err != nil {
+			exitf(exitUsage, "Error: --now: %v", err)
+		}
+		now = now.UTC()
+	}
+	futureSkew, err := owner.ParseDuration(*maxFutureSkew)
 	if err != nil {
-		return fmt.Errorf("failed to get commit log for repository %s: %w", repoPath, err)
+		exitf(exitUsage, "Error: --max-future-skew: %v", err)
+	}
+	if *dropFutureCommits && futureSkew == 0 {
+		exitf(exitUsage, "Error: --drop-future-commits requires a non-zero --max-future-skew.")
 	}
 
-	now := time.Now()
+	var pruneStaleAge time.Duration
+	if *pruneStale != "" {
+		pruneStaleAge, err = owner.ParseDuration(*pruneStale)
+		if err != nil {
+			exitf(exitUsage, "Error: --prune-stale: %v", err)
+		}
+	}
 
-	err = commitIter.ForEach(func(c *object.Commit) error {
-		// Ignore nil commits or those with zero time (can happen with merges/errors)
-		if c == nil || c.Author.When.IsZero() {
-			return nil
+	var excludeRanges []owner.DateRange
+	for _, value := range excludeDateRanges {
+		r, err := owner.ParseDateRange(value, now)
+		if err != nil {
+			exitf(exitUsage, "Error: --exclude-date-range: %v", err)
+		}
+		excludeRanges = append(excludeRanges, r)
+	}
+	var sinceTime, untilTime time.Time
+	if *since != "" {
+		if sinceTime, err = owner.ParseDateBound(*since, now, false); err != nil {
+			exitf(exitUsage, "Error: --since: %v", err)
 		}
-		rawAuthorEmail := c.Author.Email
-		// Ignore commits with empty author emails
-		if rawAuthorEmail == "" {
-			return nil
+	}
+	if *until != "" {
+		if untilTime, err = owner.ParseDateBound(*until, now, true); err != nil {
+			exitf(exitUsage, "Error: --until: %v", err)
 		}
+	}
+	if !sinceTime.IsZero() && !untilTime.IsZero() && !untilTime.After(sinceTime) {
+		exitf(exitUsage, "Error: --until must be after --since.")
+	}
+	var windowADur, windowBDur time.Duration
+	if *compare {
+		if *since != "" || *until != "" {
+			exitf(exitUsage, "Error: --compare sets its own windows and cannot be combined with --since or --until.")
+		}
+		if *remove != "" || *matrix || *findOrphansMode || *busFactorMode || *splitTopLevel || *suggestReviewers || *perRepo || *relativeTo != "" {
+			exitf(exitUsage, "Error: --compare cannot be combined with other report modes.")
+		}
+		if *format != "text" && *format != "markdown" && *format != "json" && *format != "csv" {
+			exitf(exitUsage, "Error: --compare does not support --format=%s.", *format)
+		}
+		if windowADur, err = owner.ParseDuration(*windowA); err != nil || windowADur <= 0 {
+			exitf(exitUsage, "Error: invalid --window-a %q: expected a positive age like 30d.", *windowA)
+		}
+		if windowBDur, err = owner.ParseDuration(*windowB); err != nil || windowBDur <= 0 {
+			exitf(exitUsage, "Error: invalid --window-b %q: expected a positive age like 30d.", *windowB)
+		}
+	}
 
-		// Get the canonical email using the alias map
-		canonicalEmail := getCanonicalEmail(rawAuthorEmail, aliasMap)
-		originalNormalized := strings.ToLower(strings.TrimSpace(rawAuthorEmail))
+	// The report file is closed last, after every other cleanup has run
+	defer runCleanups()
+	if err := redirectOutput(*outputPath); err != nil {
+		exitf(exitUsage, "Error: cannot open --output: %v", err)
+	}
+
+	// Remote repositories are cloned up front and then analyzed like local ones
+	repoArgs := repoPaths
+	repoPaths = resolveRemotes(repoPaths, cloneOptions{Depth: *cloneDepth, Dir: *cloneDir})
 
-		daysAgo := now.Sub(c.Author.When).Hours() / 24
-		// Ensure daysAgo is not negative (in case of clock skew)
-		if daysAgo < 0 {
-			daysAgo = 0
+	// --- Load Aliases (before processing repos) ---
+	aliasMap, err := owner.LoadAliases(*aliasesFile)
+	if err != nil {
+		// LoadAliases handles the 'not found' case gracefully if the flag was empty.
+		// Only exit if a file was specified and it failed to load/parse.
+		if *aliasesFile != "" {
+			exitf(exitUsage, "Error loading aliases: %v", err)
 		}
-		weight := math.Exp(-daysAgo / tau)
-		userScores[canonicalEmail] += weight // Use the canonical email as the key
+		// If no file was specified or only a 'not found' warning occurred, continue.
+	}
+	aliasCount := len(aliasMap)
 
-		// Record that this (canonical) user contributed to this repo
-		if _, ok := userRepos[canonicalEmail]; !ok {
-			userRepos[canonicalEmail] = make(map[string]struct{})
+	// Mailmaps compose with the aliases file, which wins on conflicts
+	var mailmaps []string
+	if *mailmapFile != "" {
+		mailmaps = []string{*mailmapFile}
+	} else if !*noMailmap {
+		for _, repoPath := range repoPaths {
+			mailmaps = append(mailmaps, filepath.Join(repoPath, ".mailmap"))
 		}
-		userRepos[canonicalEmail][repoPath] = struct{}{}
+	}
+	loadedMailmaps := mailmaps[:0]
+	for _, mailmapPath := range mailmaps {
+		mailmap, err := owner.LoadMailmap(mailmapPath)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && *mailmapFile == "" {
+				continue // Most repositories have no .mailmap
+			}
+			exitf(exitUsage, "Error loading mailmap: %v", err)
+		}
+		owner.MergeMailmap(aliasMap, mailmap)
+		loadedMailmaps = append(loadedMailmaps, mailmapPath)
+	}
 
-		// Record which alias was used for this canonical user (if it was different from the canonical)
-		if originalNormalized != canonicalEmail {
-			if _, ok := userAliases[canonicalEmail]; !ok {
-				userAliases[canonicalEmail] = make(map[string]struct{})
+	var normalizeDomains map[string]struct{}
+	if *normalizeGmail {
+		normalizeDomains = make(map[string]struct{})
+		for _, domain := range strings.Split(*normalizeDomainsFlag, ",") {
+			if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+				normalizeDomains[domain] = struct{}{}
 			}
-			userAliases[canonicalEmail][originalNormalized] = struct{}{}
 		}
+		if len(normalizeDomains) == 0 {
+			exitf(exitUsage, "Error: --normalize-gmail needs at least one domain in --normalize-domains.")
+		}
+	}
+	canonicalEmail := func(email string) string {
+		return owner.ResolveEmail(email, aliasMap, normalizeDomains)
+	}
+
+	var excludeEmailSet map[string]struct{}
+	for _, email := range excludeEmails {
+		if excludeEmailSet == nil {
+			excludeEmailSet = make(map[string]struct{})
+		}
+		excludeEmailSet[canonicalEmail(email)] = struct{}{}
+	}
+	var excludePatterns []*regexp.Regexp
+	for _, pattern := range excludeEmailRegexes {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			exitf(exitUsage, "Error: invalid --exclude-email-regex %q: %v", pattern, err)
+		}
+		excludePatterns = append(excludePatterns, re)
+	}
+	renameScore := 0
+	if *followRenames {
+		renameScore = *renameThreshold
+	}
+	var excludeMessages []*regexp.Regexp
+	for _, pattern := range excludeMessageRegexes {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			exitf(exitUsage, "Error: invalid --exclude-message-regex %q: %v", pattern, err)
+		}
+		excludeMessages = append(excludeMessages, re)
+	}
 
-		return nil
-	})
+	var pathPrefixes []string
+	if *filesFrom != "" {
+		pathPrefixes, err = readPathList(*filesFrom)
+		if err != nil {
+			exitf(exitUsage, "Error reading --files-from: %v", err)
+		}
+		if len(pathPrefixes) == 0 {
+			exitf(exitUsage, "Error: --files-from lists no paths.")
+		}
+	}
+	pathPrefixes = append(pathPrefixes, files...)
+	for _, dir := range subtrees {
+		dir = strings.Trim(path.Clean(filepath.ToSlash(dir)), "/")
+		if dir == "" || dir == "." {
+			exitf(exitUsage, "Error: --path needs a directory below the repository root.")
+		}
+		pathPrefixes = append(pathPrefixes, dir+"/")
+	}
+	if *diffSpec != "" {
+		changed, err := owner.DiffPaths(repoPaths, *diffSpec)
+		if err != nil {
+			exitf(exitUsage, "Error: --diff: %v", err)
+		}
+		if len(changed) == 0 {
+			exitf(exitUsage, "Error: --diff %s changes no files.", *diffSpec)
+		}
+		owner.Infof("Scoring owners of %d files changed in %s.", len(changed), *diffSpec)
+		pathPrefixes = append(pathPrefixes, changed...)
+	}
+	var keyring string
+	if *keyringFile != "" {
+		keyring, err = owner.LoadKeyring(*keyringFile)
+		if err != nil {
+			exitf(exitUsage, "Error: %v", err)
+		}
+	}
+	var dependents owner.DependencyMap
+	if *depsFile != "" {
+		dependents, err = owner.LoadDependencies(*depsFile)
+		if err != nil {
+			exitf(exitUsage, "Error: %v", err)
+		}
+	}
+	var prior []codeownersRule
+	if *codeownersPrior != "" {
+		prior, err = loadCodeowners(*codeownersPrior)
+		if err != nil {
+			exitf(exitUsage, "Error loading --codeowners-prior: %v", err)
+		}
+	}
+	usernames, err := loadUsernames(*usernamesFile)
 	if err != nil {
-		// Report error iterating commits, but allow main function to continue if desired
-		return fmt.Errorf("error iterating commits in %s: %w", repoPath, err)
+		exitf(exitUsage, "Error loading usernames: %v", err)
 	}
 
-	fmt.Printf("Finished processing %s.\n", repoPath)
-	return nil // Success for this repository
-}
+	// Pick a seed when none was given; it is printed so the run can be repeated
+	if !flagWasSet("seed") {
+		*seed = rand.Uint64()
+	}
 
-func main() {
-	// --- Parameters ---
-	tau := flag.Float64("tau", 365.0, "Temporal decay parameter (in days)")
-	count := flag.Int("count", 10, "Number of most likely owners to display")
-	bonusPerRepo := flag.Float64("bonus-per-repo", 0.1, "Multiplicative bonus factor per additional repository (e.g., 0.1 means +10% for the 2nd repo)")
-	aliasesFile := flag.String("aliases-file", "", "Optional path to a TOML file defining email aliases (e.g., aliases.toml)") // New flag
-	flag.Parse()
+	// --- Processing ---
+	for i, ext := range extensions {
+		if extensions[i] = owner.NormalizeExtension(ext); extensions[i] == "" {
+			exitf(exitUsage, "Error: --ext needs an extension such as go or .tsx.")
+		}
+	}
+	if *extScale && len(extensions) == 0 {
+		exitf(exitUsage, "Error: --ext-scale requires --ext.")
+	}
 
-	// --- Input Validation ---
-	repoPaths := flag.Args()
-	if len(repoPaths) == 0 {
-		fmt.Println("Usage: go run main.go [--tau=...] [--count=...] [--bonus-per-repo=...] [--aliases-file=...] <local_repo_path1> [local_repo_path2] ...")
-		os.Exit(1)
+	opts := owner.ScanOptions{
+		Tau:              *tau,
+		Decay:            *decay,
+		RawCount:         *rawCount,
+		Now:              now,
+		MaxFutureSkew:    futureSkew,
+		DropFutureDated:  *dropFutureCommits,
+		AliasMap:         aliasMap,
+		NormalizeDomains: normalizeDomains,
+		ExcludeRanges:    excludeRanges,
+		Since:            sinceTime,
+		Until:            untilTime,
+		SampleRate:       *sampleRate,
+		Seed:             *seed,
+		PathPrefixes:     pathPrefixes,
+		IgnorePaths:      ignorePaths,
+		Extensions:       extensions,
+		ScaleByExtension: *extScale,
+		Identity:         *identity,
+		CommitterWeight:  *committerWeight,
+		CoauthorWeight:   *coauthorWeight,
+		TrackFiles:       *format == "dot" || *format == "editor" || *format == "codeowners" || *sqliteOut != "",
+		TicketPattern:    ticketPattern,
+		TicketBonus:      *ticketBonus,
+		DedupAcrossRepos: *dedupAcrossRepos,
+		DiscountReverts:  *discountReverts,
+		RevertWeight:     *revertWeight,
+		MaintenanceBonus: *maintenanceBonus,
+		Strict:           *strict,
+		FullBlame:        *fullBlame,
+		ReleaseBonus:     *releaseBonus,
+		SignedBonus:      *signedBonus,
+		OriginBonus:      *originBonus,
+		SignedOnly:       *signedOnly,
+		VerifySignatures: *verifySignatures,
+		Keyring:          keyring,
+		ReleaseWindow:    time.Duration(*releaseWindowDays * 24 * float64(time.Hour)),
+		Progress:         *progress && isTerminal(os.Stderr),
+		BlameWorkers:     *blameWorkers,
+		BlameCache:       !*noBlameCache,
+		CacheDir:         *cacheDir,
+		RecordCommits:    *sqliteOut != "" || *explainCommits > 0,
+		AllBranches:      *allBranches,
+		AllRefs:          *allRefs,
+		NoDetectDotGit:   *noDetectDotGit,
+		WeightFloor:      *weightFloor,
+		ReleasedOnly:     *releasedOnly,
+		Ref:              *ref,
+		ExcludeEmails:    excludeEmailSet,
+		ExcludePatterns:  excludePatterns,
+		ExcludeMessages:  excludeMessages,
+		RenameScore:      renameScore,
+		ExcludeBots:      *excludeBots,
+		WeightByLines:    *weightByLines,
+		WeightBy:         *weightBy,
+		InvalidEmails:    invalidEmails,
+		Dependents:       dependents,
 	}
-	if *bonusPerRepo < 0 {
-		fmt.Println("Error: --bonus-per-repo cannot be negative.")
-		os.Exit(1)
+
+	rank := owner.RankOptions{
+		BonusPerRepo: *bonusPerRepo,
+		Now:          opts.Now,
+		PruneStale:   pruneStaleAge,
+		MinScore:     *minScore,
+		MinRepos:     *minRepos,
+		SortBy:       *sortBy,
+	}
+	if config != nil && len(config.Repos) > 0 {
+		opts.RepoTau, rank.RepoBonus, opts.RepoWeight = config.repoOverrides(repoArgs, repoPaths)
+	}
+	if len(repoWeightPatterns) > 0 {
+		// --repo-weight takes precedence over the config file for the repositories it matches
+		if opts.RepoWeight == nil {
+			opts.RepoWeight = make(map[string]float64)
+		}
+		patterns := make([]string, 0, len(repoWeightPatterns))
+		for pattern := range repoWeightPatterns {
+			patterns = append(patterns, pattern)
+		}
+		for i, arg := range repoArgs {
+			if best := matchRepoPattern(patterns, arg); best != "" {
+				opts.RepoWeight[repoPaths[i]] = repoWeightPatterns[best]
+			}
+		}
 	}
 
-	// --- Load Aliases (before processing repos) ---
-	aliasMap, err := loadAliases(*aliasesFile)
-	if err != nil {
-		// loadAliases handles the 'not found' case gracefully if the flag was empty.
-		// Only exit if a file was specified and it failed to load/parse.
-		if *aliasesFile != "" {
-			fmt.Fprintf(os.Stderr, "Error loading aliases: %v\n", err)
-			os.Exit(1)
+	// The first Ctrl-C (or the timeout) stops the scan and prints partial results; a second one aborts
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	if *mergeByName {
+		merged := owner.NameAliases(repoPaths, opts)
+		owner.AddAliases(aliasMap, merged)
+		owner.Infof("Merged %d emails into other identities by author name.", len(merged))
+	}
+	owner.Infof("Analyzing %d repositories with tau=%.1f days...", len(repoPaths), *tau)
+	if *topKPrecise > 0 {
+		opts.Candidates = owner.TopKCandidates(repoPaths, opts, *topKPrecise)
+		owner.Infof("Scoring only the top %d authors by commit count.", len(opts.Candidates))
+	}
+	owner.Infof("Using seed %d (pass --seed=%d to reproduce this run).", *seed, *seed)
+
+	if *compare {
+		entries, failed := runCompare(ctx, repoPaths, opts, rank, windowADur, windowBDur, *count)
+		aStart, bStart := compareWindows(now, windowADur, windowBDur)
+		params := append(runParameters(opts, rank, *aliasesFile, loadedMailmaps, *mergeByName, *relativeTo),
+			parameter{"window_a", aStart.UTC().Format(time.RFC3339) + ".." + bStart.UTC().Format(time.RFC3339)},
+			parameter{"window_b", bStart.UTC().Format(time.RFC3339) + ".." + now.UTC().Format(time.RFC3339)})
+		switch *format {
+		case "markdown":
+			printTrendMarkdown(entries, *windowA, *windowB)
+			printParametersMarkdown(params)
+		case "json":
+			if err := printTrendJSON(entries); err != nil {
+				exitf(exitUsage, "Error writing JSON: %v", err)
+			}
+		case "csv":
+			if err := printTrendCSV(entries); err != nil {
+				exitf(exitUsage, "Error writing CSV: %v", err)
+			}
+		default:
+			printTrendText(entries, *windowA, *windowB)
+			printParametersText(params)
 		}
-		// If no file was specified or only a 'not found' warning occurred, continue.
+		if len(failed) == len(repoPaths) {
+			exitf(exitAllReposFailed, "Error: all %d repositories failed to process.", len(repoPaths))
+		}
+		exitOnPartialFailure(failed)
+		return
 	}
 
-	// --- Processing ---
-	// Global maps to accumulate data across all repositories
-	globalUserScores := make(map[string]float64)            // canonical_email -> Accumulated base score
-	userRepos := make(map[string]map[string]struct{})       // canonical_email -> Set of repo paths contributed to
-	userAliasesUsed := make(map[string]map[string]struct{}) // canonical_email -> Set of alias emails used for this canonical
+	if *splitTopLevel {
+		failed := runSplitTopLevel(ctx, repoPaths, opts, rank, *count, *format)
+		params := runParameters(opts, rank, *aliasesFile, loadedMailmaps, *mergeByName, *relativeTo)
+		if *format == "markdown" {
+			printParametersMarkdown(params)
+		} else {
+			printParametersText(params)
+		}
+		if len(failed) == len(repoPaths) {
+			exitf(exitAllReposFailed, "Error: all %d repositories failed to process.", len(repoPaths))
+		}
+		exitOnPartialFailure(failed)
+		return
+	}
 
-	fmt.Printf("Analyzing %d repositories with tau=%.1f days...\n", len(repoPaths), *tau)
+	if *remove != "" || *matrix || *findOrphansMode || *topFilesMode {
+		opts.TrackFiles = true // All of these look at per-file ownership
+	}
+	if *recencyMode {
+		opts.AgeBuckets = ageBuckets
+	}
 
-	// Iterate over each provided repository path
-	for _, repoPath := range repoPaths {
-		// Pass aliasMap and accumulating maps to the processing function
-		err := processRepoCommits(repoPath, *tau, aliasMap, globalUserScores, userRepos, userAliasesUsed)
-		if err != nil {
-			// Print a warning if a repo fails, but continue with the others
-			fmt.Fprintf(os.Stderr, "Warning: Skipping repository %s due to error: %v\n", repoPath, err)
+	// Accumulate data across all repositories
+	data, failed := owner.ScanRepos(ctx, repoPaths, opts)
+	if len(failed) == len(repoPaths) {
+		exitf(exitAllReposFailed, "Error: all %d repositories failed to process.", len(repoPaths))
+	}
+
+	if *remove != "" {
+		removed := canonicalEmail(*remove)
+		if _, ok := data.Scores[removed]; !ok {
+			exitf(exitUsage, "Error: --remove %s has no scored commits.", removed)
 		}
+		owner.Infof("Scanning again without %s...", removed)
+		withoutOpts := opts
+		withoutOpts.ExcludeEmails = map[string]struct{}{removed: {}}
+		for email := range opts.ExcludeEmails {
+			withoutOpts.ExcludeEmails[email] = struct{}{}
+		}
+		without, _ := owner.ScanRepos(ctx, repoPaths, withoutOpts)
+		printRemovalImpact(removed, removalImpact(data, without, *orphanThreshold), *orphanThreshold)
+		exitOnPartialFailure(failed)
+		return
+	}
+
+	if *anonymize {
+		tokens := owner.Anonymize(data, *salt)
+		// From here on the baseline contributor is known by their token
+		if token, ok := tokens[canonicalEmail(*relativeTo)]; ok && *relativeTo != "" {
+			*relativeTo = token
+		}
+	}
+
+	if *reportInvalidEmails {
+		printInvalidEmails(os.Stderr, data.Invalid)
+	}
+
+	if *findOrphansMode {
+		cutoff := now.Add(-pruneStaleAge)
+		printOrphans(findOrphans(data, *orphanThreshold, cutoff), cutoff)
+		exitOnPartialFailure(failed)
+		return
 	}
 
 	// --- Final Calculation and Sorting ---
-	if len(globalUserScores) == 0 {
-		fmt.Println("No commit data found or processed successfully.")
-		os.Exit(0)
-	}
-
-	// Convert accumulated data into OwnerScore slice, applying the bonus
-	owners := make([]OwnerScore, 0, len(globalUserScores))
-	for canonicalEmail, rawScore := range globalUserScores {
-		repoSet := userRepos[canonicalEmail] // The set of repos for this user
-		repoCount := len(repoSet)
-
-		aliasesSet := userAliasesUsed[canonicalEmail] // The set of aliases used for this canonical email
-		aliases := make([]string, 0, len(aliasesSet))
-		for alias := range aliasesSet {
-			aliases = append(aliases, alias)
-		}
-		sort.Strings(aliases) // Sort for consistent output
-
-		// Calculate the bonus factor
-		// If contributed to 1 repo, repoCount = 1, bonus = 1.0 + (1-1)*rate = 1.0
-		// If contributed to 2 repos, repoCount = 2, bonus = 1.0 + (2-1)*rate = 1.0 + rate
-		// If contributed to 3 repos, repoCount = 3, bonus = 1.0 + (3-1)*rate = 1.0 + 2*rate
-		bonusFactor := 1.0
-		if repoCount > 1 {
-			bonusFactor = 1.0 + (float64(repoCount-1) * (*bonusPerRepo))
-		}
-
-		finalScore := rawScore * bonusFactor
-
-		owners = append(owners, OwnerScore{
-			Email:       canonicalEmail, // Always use the canonical email
-			Score:       finalScore,
-			RepoCount:   repoCount,
-			RawScore:    rawScore, // Store the raw score for potential debugging/info
-			AliasesUsed: aliases,  // Save the aliases that were merged into this one
-		})
-	}
-
-	// Sort by final score (Score) descending
-	sort.Slice(owners, func(i, j int) bool {
-		// If scores are equal, break ties by repo count (more is better)
-		if owners[i].Score == owners[j].Score {
-			// If repo counts are also equal, break ties alphabetically by email for stable order
-			if owners[i].RepoCount == owners[j].RepoCount {
-				return owners[i].Email < owners[j].Email
+	if len(data.Scores) == 0 {
+		if *errorOnEmpty || *strict {
+			exitf(exitEmptyResult, "No commit data found or processed successfully.")
+		}
+		exitf(exitOK, "No commit data found or processed successfully.")
+	}
+	// Thin data after aggressive filtering makes the ranking statistically weak
+	lowCoverage := data.Scored < *minCoverage
+	if lowCoverage {
+		owner.Warnf("WARNING: only %d commits were scored, below --min-coverage=%d. The ranking may be unreliable.", data.Scored, *minCoverage)
+	}
+	owners, pruned := owner.RankOwners(data, rank)
+	if pruned > 0 {
+		owner.Infof("Pruned %d owners %s.", pruned, pruneReason(rank, *pruneStale))
+	}
+	if *suggestAliasesMode {
+		printAliasSuggestions(suggestAliases(owners, aliasSimilarity{MaxDistance: *aliasMaxDistance, MinLocal: *aliasMinLocal}))
+		exitOnPartialFailure(failed)
+		return
+	}
+	// Deferred exits run last to first: a partial failure wins over low coverage, which wins over the bus factor.
+	// The bus factor gate looks at every ranked owner, not just the --count shown
+	if *failUnderBusFactor > 0 {
+		defer exitOnLowBusFactor(busFactors(owners, data, *busThreshold)[0], *failUnderBusFactor)
+	}
+	if lowCoverage && *strictCoverage {
+		defer exitOnLowCoverage(data.Scored, *minCoverage)
+	}
+	// Results are still printed when some repositories were skipped, but the
+	// exit code reports the partial failure
+	defer exitOnPartialFailure(failed)
+	if *relativeTo != "" {
+		baseline := canonicalEmail(*relativeTo)
+		if err := owner.MakeRelative(owners, baseline); err != nil {
+			exitf(exitUsage, "Error: --relative-to: %v", err)
+		}
+	}
+	params := runParameters(opts, rank, *aliasesFile, loadedMailmaps, *mergeByName, *relativeTo)
+	params = append(params, parameter{"repositories", strconv.Itoa(len(repoPaths))})
+	if *anonymize {
+		params = append(params, parameter{"anonymized", "true"})
+	}
+	// The database gets the full ranking, not just the --count shown
+	if *sqliteOut != "" {
+		if err := writeSQLite(*sqliteOut, owners, data, params); err != nil {
+			exitf(exitUsage, "Error writing --sqlite-out %s: %v", *sqliteOut, err)
+		}
+		owner.Infof("Wrote %d owners and %d commit credits to %s.", len(owners), len(data.CommitLog), *sqliteOut)
+	}
+	if *parametersOut != "" {
+		if err := writeParameters(*parametersOut, params); err != nil {
+			exitf(exitUsage, "Error writing --parameters-out %s: %v", *parametersOut, err)
+		}
+	}
+	// The bus factor looks at every owner, not just the --count shown
+	if *busFactorMode {
+		reports := busFactors(owners, data, *busThreshold)
+		switch *format {
+		case "markdown":
+			printBusFactorMarkdown(reports, *busThreshold)
+			printParametersMarkdown(params)
+		case "json":
+			if err := printBusFactorJSON(reports); err != nil {
+				exitf(exitUsage, "Error writing JSON: %v", err)
 			}
-			return owners[i].RepoCount > owners[j].RepoCount
+		default:
+			printBusFactorText(reports, *busThreshold)
+			printParametersText(params)
 		}
-		return owners[i].Score > owners[j].Score
-	})
+		return
+	}
+	if *recencyMode {
+		rows := recencyHistogram(owner.TopN(owners, *count), data.AgeCounts, ageBuckets)
+		switch *format {
+		case "markdown":
+			printRecencyMarkdown(rows, ageBuckets)
+			printParametersMarkdown(params)
+		case "json":
+			if err := printRecencyJSON(rows); err != nil {
+				exitf(exitUsage, "Error writing JSON: %v", err)
+			}
+		case "csv":
+			if err := printRecencyCSV(rows, ageBuckets); err != nil {
+				exitf(exitUsage, "Error writing CSV: %v", err)
+			}
+		default:
+			printRecencyText(rows, ageBuckets)
+			printParametersText(params)
+		}
+		return
+	}
+	if *topFilesMode {
+		files := topFiles(data.Files, *count)
+		switch *format {
+		case "markdown":
+			printTopFilesMarkdown(files)
+			printParametersMarkdown(params)
+		case "json":
+			if err := printTopFilesJSON(files); err != nil {
+				exitf(exitUsage, "Error writing JSON: %v", err)
+			}
+		case "csv":
+			if err := printTopFilesCSV(files); err != nil {
+				exitf(exitUsage, "Error writing CSV: %v", err)
+			}
+		default:
+			printTopFilesText(files)
+			printParametersText(params)
+		}
+		return
+	}
+	var perRepoRankings []repoRanking
+	if *perRepo {
+		perRepoRankings = repoRankings(data, owners, *count)
+	}
+	owners = owner.TopN(owners, *count)
+	if *githubResolve {
+		resolveGitHubLogins(owners, usernames, newGitHubResolver(&http.Client{Timeout: 15 * time.Second}, *githubToken, now))
+	}
 
 	// --- Output ---
+	out := outputOptions{
+		Sampling:  *sampleRate < 1,
+		Explain:   *explain,
+		MultiRepo: len(repoPaths) > 1,
+		RawCount:  *rawCount,
+	}
+	if *explainCommits > 0 && len(owners) > 0 {
+		out.TopCommits = topCommits(data.CommitLog, owners[0].Email, *explainCommits)
+		out.Now = now
+	}
+	if *suggestReviewers {
+		fmt.Println(renderSuggestion(*suggestTemplate, owners, usernames))
+		return
+	}
+	if *matrix {
+		mo := matrixOptions{Depth: *matrixDepth, Breadth: *matrixBreadth, Owners: *matrixOwners}
+		switch *format {
+		case "markdown":
+			printMatrixMarkdown(buildMatrix(data, mo.Depth), mo)
+			printParametersMarkdown(params)
+			return
+		case "json":
+			if err := printMatrixJSON(buildMatrix(data, mo.Depth), mo); err != nil {
+				exitf(exitUsage, "Error writing JSON: %v", err)
+			}
+			return
+		}
+		printMatrixText(buildMatrix(data, mo.Depth), mo)
+		printParametersText(params)
+		return
+	}
+	switch *format {
+	case "markdown":
+		if lowCoverage {
+			fmt.Printf("> **Warning:** only %d commits were scored (minimum %d). This ranking may be unreliable.\n\n", data.Scored, *minCoverage)
+		}
+		printMarkdown(owners, out)
+		printRepoRankingsMarkdown(perRepoRankings)
+		printParametersMarkdown(params)
+		return
+	case "dot":
+		printDot(owners, data.Files, params)
+		return
+	case "editor":
+		printEditor(data.Files, files)
+		return
+	case "compact":
+		printCompact(owners)
+		return
+	case "json":
+		if *jsonEnvelope {
+			if err := printJSONEnvelope(owners, data.Skipped, params, out); err != nil {
+				exitf(exitUsage, "Error writing JSON: %v", err)
+			}
+			return
+		}
+		if err := printJSON(owners, out); err != nil {
+			exitf(exitUsage, "Error writing JSON: %v", err)
+		}
+		return
+	case "yaml":
+		if err := printYAML(owners, params, out); err != nil {
+			exitf(exitUsage, "Error writing YAML: %v", err)
+		}
+		return
+	case "csv":
+		if err := printCSV(owners, out); err != nil {
+			exitf(exitUsage, "Error writing CSV: %v", err)
+		}
+		return
+	case "codeowners":
+		existing, err := headFiles(repoPaths[0], *ref)
+		if err != nil {
+			exitf(exitAllReposFailed, "Error listing files of %s: %v", repoPaths[0], err)
+		}
+		ownedFiles := data.Files
+		if *codeownersDepth > 0 {
+			ownedFiles, existing = codeownersDirs(data.Files, existing, *codeownersDepth)
+		}
+		printCodeowners(assignCodeowners(ownedFiles, existing, usernames, prior, *codeownersMargin), prior)
+		return
+	}
+
 	fmt.Println("\n--- Top Likely Owners ---")
 	fmt.Printf("Showing top %d contributors based on recent activity across %d specified repositories.\n", *count, len(repoPaths))
 	fmt.Printf("Bonus per additional repo: %.1f%%\n", *bonusPerRepo*100)
-	if len(aliasMap) > 0 {
+	if lowCoverage {
+		fmt.Printf("WARNING: only %d commits were scored (minimum %d). This ranking may be unreliable.\n", data.Scored, *minCoverage)
+	}
+	if pruned > 0 {
+		fmt.Printf("Pruned %d owners %s.\n", pruned, pruneReason(rank, *pruneStale))
+	}
+	if *relativeTo != "" {
+		fmt.Printf("Scores are relative to %s (= 1.00).\n", canonicalEmail(*relativeTo))
+	}
+	if *sampleRate < 1 {
+		fmt.Printf("Sampling %.1f%% of commits: scores are estimates with ~95%% margins of error.\n", *sampleRate*100)
+	}
+	fmt.Printf("Random seed: %d\n", *seed)
+	if len(loadedMailmaps) > 0 {
+		fmt.Printf("Mailmap loaded from: %s\n", strings.Join(loadedMailmaps, ", "))
+	}
+	if aliasCount > 0 {
 		fmt.Printf("Aliases loaded from: %s\n", *aliasesFile)
 	} else if *aliasesFile != "" {
 		// File was specified but no aliases loaded (e.g., not found, empty, or unparseable)
@@ -292,23 +1126,11 @@ func main() {
 	}
 	fmt.Println("")
 
-	// Display only the top "count" results
-	limit := *count
-	if len(owners) < limit {
-		limit = len(owners)
-	}
-
-	for i, owner := range owners[:limit] {
-		aliasInfo := ""
-		if len(owner.AliasesUsed) > 0 {
-			// Add alias information if it exists for this owner
-			aliasInfo = fmt.Sprintf(" (aliases: %s)", strings.Join(owner.AliasesUsed, ", "))
-		}
-		fmt.Printf("%d. %s (Score: %.2f, Repos: %d)%s\n",
-			i+1,
-			owner.Email,
-			owner.Score,
-			owner.RepoCount,
-			aliasInfo)
+	if *format == "table" {
+		printTable(owners, out)
+	} else {
+		printText(owners, out)
 	}
+	printRepoRankingsText(perRepoRankings)
+	printParametersText(params)
 }
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+	"github.com/mateobur/gitowner/pkg/owner"
+	"gopkg.in/yaml.v3"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files in testdata")
+
+// gitownerBin is the gitowner command built for the tests.
+var gitownerBin string
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	dir, err := os.MkdirTemp("", "gitowner-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	gitownerBin = filepath.Join(dir, "gitowner")
+	if out, err := exec.Command("go", "build", "-o", gitownerBin, ".").CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "building gitowner: %v\n%s", err, out)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// result is what a run of gitowner printed and its exit code.
+type result struct {
+	Stdout, Stderr string
+	Code           int
+}
+
+// run runs gitowner with args as of testrepo.Now and a fixed seed, from a
+// temporary directory.
+func run(t *testing.T, args ...string) result {
+	t.Helper()
+	return runIn(t, t.TempDir(), "", args...)
+}
+
+// runIn runs gitowner from dir with stdin as its standard input.
+func runIn(t *testing.T, dir, stdin string, args ...string) result {
+	t.Helper()
+	fixed := []string{"--now=" + testrepo.Now.Format(time.RFC3339), "--seed=1"}
+	cmd := exec.Command(gitownerBin, append(fixed, args...)...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		t.Fatal(err)
+	}
+	return result{stdout.String(), stderr.String(), cmd.ProcessState.ExitCode()}
+}
+
+// mustRun is run, failing the test unless gitowner exits with code 0.
+func mustRun(t *testing.T, args ...string) string {
+	t.Helper()
+	res := run(t, args...)
+	if res.Code != 0 {
+		t.Fatalf("gitowner %s: exit code %d\n%s", strings.Join(args, " "), res.Code, res.Stderr)
+	}
+	return res.Stdout
+}
+
+// golden compares got with testdata/name, after replacing the given paths by
+// placeholders, or rewrites the file with -update.
+func golden(t *testing.T, name, got string, paths map[string]string) {
+	t.Helper()
+	for path, placeholder := range paths {
+		got = strings.ReplaceAll(got, path, placeholder)
+	}
+	file := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(file, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s (run go test -update to accept it):\ngot:\n%s\nwant:\n%s", file, got, want)
+	}
+}
+
+// near reports whether a and b are equal up to rounding.
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+// ownersRepo builds a repository where alice committed most and longest ago,
+// bob recently, and carol once, in several directories.
+func ownersRepo(t *testing.T) *testrepo.Repo {
+	t.Helper()
+	r := testrepo.New(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(700), map[string]string{"README.md": "hello\n", "svc/api/main.go": testrepo.Lines(20)})
+	r.Commit("alice@example.com", testrepo.DaysAgo(400), map[string]string{"svc/api/main.go": testrepo.Lines(25)})
+	r.Commit("alice@example.com", testrepo.DaysAgo(90), map[string]string{"svc/api/handler.go": testrepo.Lines(10)})
+	r.Commit("bob@example.com", testrepo.DaysAgo(30), map[string]string{"docs/guide.md": testrepo.Lines(5)})
+	r.Commit("bob@example.com", testrepo.DaysAgo(3), map[string]string{"docs/guide.md": testrepo.Lines(8)})
+	r.Commit("carol@example.com", testrepo.DaysAgo(200), map[string]string{"svc/api/handler.go": testrepo.Lines(12)})
+	return r
+}
+
+func TestScoresTemporaryRepo(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(300), nil)
+	r.Commit("bob@example.com", testrepo.DaysAgo(200), nil)
+	r.Commit("alice@example.com", testrepo.DaysAgo(10), nil)
+	r.Commit("alice@example.com", testrepo.DaysAgo(1), nil)
+
+	out := mustRun(t, r.Dir)
+	alice, bob := strings.Index(out, "1. alice@example.com (Score: "), strings.Index(out, "2. bob@example.com (Score: ")
+	if alice < 0 || bob < alice {
+		t.Errorf("want alice ranked first and bob second:\n%s", out)
+	}
+	if strings.Contains(out, "3. ") {
+		t.Errorf("want exactly two owners:\n%s", out)
+	}
+}
+
+func TestDefaultOutputGolden(t *testing.T) {
+	r := ownersRepo(t)
+	out := mustRun(t, r.Dir)
+	golden(t, "default.golden", out, map[string]string{r.Dir: "REPO"})
+}
+
+func TestExcludeEmailRegexIgnoresCase(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("dev@example.com", testrepo.DaysAgo(3), nil)
+	r.Commit("release-bot@example.com", testrepo.DaysAgo(1), nil)
+	r.Commit("Deploy.Robot@Example.com", testrepo.DaysAgo(1), nil)
+	out := mustRun(t, "--format=compact", "--exclude-email-regex=^RELEASE-", "--exclude-email-regex=deploy\\.robot", r.Dir)
+	if got := strings.TrimSpace(out); !strings.HasPrefix(got, "dev@example.com ") || strings.Contains(got, "\n") {
+		t.Errorf("got %q, want only dev@example.com", got)
+	}
+}
+
+func TestExcludeMessageRegex(t *testing.T) {
+	r := testrepo.New(t)
+	commit := func(email, message string) {
+		sig := testrepo.Sig(email, testrepo.DaysAgo(1))
+		r.CommitWith(sig, sig, message, nil)
+	}
+	commit("dev@example.com", "Fix the parser\n")
+	commit("bot@example.com", "chore: bump version to 1.2\n")
+	commit("release@example.com", "CHORE: Bump version to 1.3\n")
+	commit("merger@example.com", "Merge branch 'feature'\n")
+	commit("writer@example.com", "Document that we never chore: bump by hand\n")
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{nil, []string{"bot@example.com", "dev@example.com", "merger@example.com", "release@example.com", "writer@example.com"}},
+		{[]string{"--exclude-message-regex=^chore: bump"}, []string{"dev@example.com", "merger@example.com", "writer@example.com"}},
+		{[]string{"--exclude-message-regex=(?-i)^chore: bump"}, []string{"dev@example.com", "merger@example.com", "release@example.com", "writer@example.com"}},
+		{[]string{"--exclude-message-regex=^chore:", "--exclude-message-regex=^merge branch"}, []string{"dev@example.com", "writer@example.com"}},
+	}
+	for _, tt := range tests {
+		args := append(append([]string{"--format=compact"}, tt.args...), r.Dir)
+		got := emails(mustRun(t, args...))
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: got %v, want %v", tt.args, got, tt.want)
+		}
+	}
+
+	if res := run(t, "--exclude-message-regex=(", r.Dir); res.Code != exitUsage {
+		t.Errorf("invalid pattern: exit code %d, want %d", res.Code, exitUsage)
+	}
+}
+
+func TestEmptyRepoIsNotAnError(t *testing.T) {
+	r := testrepo.New(t)
+	res := run(t, r.Dir)
+	if res.Code != exitOK || strings.Contains(res.Stderr, "Warning") || !strings.Contains(res.Stderr, "has no commits yet") {
+		t.Errorf("exit code %d, stderr %q", res.Code, res.Stderr)
+	}
+}
+
+func TestRawCount(t *testing.T) {
+	a, b := testrepo.New(t), testrepo.New(t)
+	a.Commit("alice@example.com", testrepo.DaysAgo(900), nil)
+	a.Commit("alice@example.com", testrepo.DaysAgo(300), nil)
+	a.Commit("alice@example.com", testrepo.DaysAgo(1), nil)
+	a.Commit("bob@example.com", testrepo.DaysAgo(2), nil)
+	b.Commit("bob@example.com", testrepo.DaysAgo(40), nil)
+	b.Commit("bob@example.com", testrepo.DaysAgo(4), nil)
+	b.Commit("bob@example.com", testrepo.DaysAgo(3), nil)
+
+	var owners []struct {
+		owner.OwnerScore
+		Commits float64 `json:"commits"`
+	}
+	out := mustRun(t, "--raw-count", "--format=json", a.Dir, b.Dir)
+	if err := json.Unmarshal([]byte(out), &owners); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if strings.Contains(out, `"score"`) {
+		t.Errorf("counts are still named score:\n%s", out)
+	}
+	for _, o := range owners {
+		if o.Commits != float64(o.CommitCount) || o.BonusFactor != 1 {
+			t.Errorf("%s: count %g and bonus %g for %d commits", o.Email, o.Commits, o.BonusFactor, o.CommitCount)
+		}
+	}
+	if len(owners) != 2 || owners[0].CommitCount != 4 || owners[1].CommitCount != 3 {
+		t.Errorf("got %+v, want bob with 4 commits, then alice with 3", owners)
+	}
+
+	if out := mustRun(t, "--raw-count", "--bonus-per-repo=0.5", "--format=compact", a.Dir, b.Dir); !strings.HasPrefix(out, "bob@example.com 6.0000\n") {
+		t.Errorf("explicit bonus: got %q, want bob at 4 commits times 1.5", out)
+	}
+	if out := mustRun(t, "--raw-count", a.Dir); !strings.Contains(out, "alice@example.com (Commits: 3,") {
+		t.Errorf("text output does not show counts as whole commits:\n%s", out)
+	}
+	if out := mustRun(t, "--raw-count", "--format=markdown", a.Dir); !strings.Contains(out, "| alice@example.com | alice | 3 |") {
+		t.Errorf("Markdown output does not show counts as whole commits:\n%s", out)
+	}
+	records, err := csv.NewReader(strings.NewReader(mustRun(t, "--raw-count", "--format=csv", a.Dir))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[0][3] != "commits" || records[1][3] != "3" {
+		t.Errorf("CSV output: got header %v and row %v, want 3 under commits", records[0], records[1])
+	}
+	var doc struct {
+		Owners []map[string]any `yaml:"owners"`
+	}
+	if err := yaml.Unmarshal([]byte(mustRun(t, "--raw-count", "--format=yaml", a.Dir)), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc.Owners[0]["score"]; ok || doc.Owners[0]["commits"] != 3 {
+		t.Errorf("YAML output: got %v, want 3 under commits", doc.Owners[0])
+	}
+}
+
+func TestAsOfIsReproducible(t *testing.T) {
+	r := ownersRepo(t)
+	for _, format := range []string{"text", "json", "csv"} {
+		first := mustRun(t, "--format="+format, r.Dir)
+		if second := mustRun(t, "--format="+format, r.Dir); second != first {
+			t.Errorf("--format=%s: two runs differ:\n%s\n---\n%s", format, first, second)
+		}
+		later := mustRun(t, "--format="+format, "--as-of=2025-06-01", r.Dir)
+		if later == first {
+			t.Errorf("--format=%s: moving --as-of a year did not change the output", format)
+		}
+	}
+}
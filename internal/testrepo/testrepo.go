@@ -0,0 +1,296 @@
+// Package testrepo builds throwaway git repositories for the tests of
+// gitowner and its library.
+//
+// Commits are written straight into the object database, which is much
+// faster than going through a work tree. The files of each commit are also
+// written to disk, but the index is never updated.
+package testrepo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Now is the reference time tests score against, so commit ages are exact.
+var Now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// DaysAgo returns the time days before Now.
+func DaysAgo(days float64) time.Time {
+	return Now.Add(-time.Duration(days * 24 * float64(time.Hour)))
+}
+
+// Repo is a repository in a temporary directory.
+type Repo struct {
+	t      testing.TB
+	Dir    string
+	Repo   *git.Repository
+	branch string                       // Checked out branch
+	files  map[string]map[string]string // branch -> path -> content at its tip
+	n      int                          // Commits made so far, to keep generated contents unique
+}
+
+// New initializes an empty repository, on branch master, in a temporary
+// directory removed when the test ends.
+func New(t testing.TB) *Repo {
+	t.Helper()
+	return initRepo(t, t.TempDir(), false)
+}
+
+// NewAt initializes an empty repository in dir, which is created if needed.
+func NewAt(t testing.TB, dir string) *Repo {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return initRepo(t, dir, false)
+}
+
+// NewBare initializes an empty bare repository.
+func NewBare(t testing.TB) *Repo {
+	t.Helper()
+	return initRepo(t, t.TempDir(), true)
+}
+
+func initRepo(t testing.TB, dir string, bare bool) *Repo {
+	t.Helper()
+	repo, err := git.PlainInit(dir, bare)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("master"))
+	if err := repo.Storer.SetReference(head); err != nil {
+		t.Fatal(err)
+	}
+	return &Repo{t: t, Dir: dir, Repo: repo, branch: "master", files: map[string]map[string]string{"master": {}}}
+}
+
+// Sig returns a signature for email, named after its local part.
+func Sig(email string, when time.Time) *object.Signature {
+	name, _, _ := strings.Cut(email, "@")
+	return &object.Signature{Name: name, Email: email, When: when}
+}
+
+// Commit writes files (path -> content; an empty content deletes the file)
+// and commits them as email at when, with a generated message. A nil files
+// map adds a file unique to the commit.
+func (r *Repo) Commit(email string, when time.Time, files map[string]string) plumbing.Hash {
+	r.t.Helper()
+	return r.CommitWith(Sig(email, when), Sig(email, when), "", files)
+}
+
+// CommitWith is Commit with explicit author, committer and message.
+func (r *Repo) CommitWith(author, committer *object.Signature, message string, files map[string]string) plumbing.Hash {
+	r.t.Helper()
+	var parents []plumbing.Hash
+	if tip, ok := r.tip(r.branch); ok {
+		parents = append(parents, tip)
+	}
+	return r.commit(author, committer, message, "", files, parents)
+}
+
+// CommitSigned is Commit with signature as the commit's PGP signature. The
+// signature is stored as is, so tests can stub it without any key.
+func (r *Repo) CommitSigned(email string, when time.Time, signature string, files map[string]string) plumbing.Hash {
+	r.t.Helper()
+	var parents []plumbing.Hash
+	if tip, ok := r.tip(r.branch); ok {
+		parents = append(parents, tip)
+	}
+	return r.commit(Sig(email, when), Sig(email, when), "", signature, files, parents)
+}
+
+// Merge merges branch into the checked out branch with a merge commit by
+// email at when. Files of branch override those of the checked out branch.
+func (r *Repo) Merge(branch, email string, when time.Time) plumbing.Hash {
+	r.t.Helper()
+	ours, ok := r.tip(r.branch)
+	theirs, ok2 := r.tip(branch)
+	if !ok || !ok2 {
+		r.t.Fatalf("cannot merge %s into %s: missing branch", branch, r.branch)
+	}
+	files := make(map[string]string)
+	for path, content := range r.files[branch] {
+		files[path] = content
+	}
+	return r.commit(Sig(email, when), Sig(email, when), "Merge branch '"+branch+"'", "", files, []plumbing.Hash{ours, theirs})
+}
+
+func (r *Repo) commit(author, committer *object.Signature, message, signature string, files map[string]string, parents []plumbing.Hash) plumbing.Hash {
+	r.t.Helper()
+	r.n++
+	if files == nil {
+		files = map[string]string{"file" + strconv.Itoa(r.n) + ".txt": strconv.Itoa(r.n) + "\n"}
+	}
+	if message == "" {
+		message = "commit " + strconv.Itoa(r.n)
+	}
+	current := r.files[r.branch]
+	for path, content := range files {
+		if content == "" {
+			delete(current, path)
+			os.Remove(r.diskPath(path))
+			continue
+		}
+		current[path] = content
+		if r.diskPath(path) != "" {
+			full := r.diskPath(path)
+			if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+				r.t.Fatal(err)
+			}
+			if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+				r.t.Fatal(err)
+			}
+		}
+	}
+	tree := r.tree(current, "")
+	c := &object.Commit{
+		Author:       *author,
+		Committer:    *committer,
+		Message:      message,
+		PGPSignature: signature,
+		TreeHash:     tree,
+		ParentHashes: parents,
+	}
+	hash := r.store(c)
+	ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(r.branch), hash)
+	if err := r.Repo.Storer.SetReference(ref); err != nil {
+		r.t.Fatal(err)
+	}
+	return hash
+}
+
+// diskPath returns where path lives in the work tree, or "" in a bare
+// repository.
+func (r *Repo) diskPath(path string) string {
+	if _, err := os.Stat(filepath.Join(r.Dir, ".git")); err != nil {
+		return ""
+	}
+	return filepath.Join(r.Dir, filepath.FromSlash(path))
+}
+
+// tree stores the tree of the files under prefix and returns its hash.
+func (r *Repo) tree(files map[string]string, prefix string) plumbing.Hash {
+	blobs := make(map[string]string)
+	dirs := make(map[string]struct{})
+	for path, content := range files {
+		rest, ok := strings.CutPrefix(path, prefix)
+		if !ok {
+			continue
+		}
+		if dir, _, nested := strings.Cut(rest, "/"); nested {
+			dirs[dir] = struct{}{}
+		} else {
+			blobs[rest] = content
+		}
+	}
+	var entries []object.TreeEntry
+	for name, content := range blobs {
+		obj := r.Repo.Storer.NewEncodedObject()
+		obj.SetType(plumbing.BlobObject)
+		w, err := obj.Writer()
+		if err != nil {
+			r.t.Fatal(err)
+		}
+		w.Write([]byte(content))
+		w.Close()
+		hash, err := r.Repo.Storer.SetEncodedObject(obj)
+		if err != nil {
+			r.t.Fatal(err)
+		}
+		entries = append(entries, object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: hash})
+	}
+	for dir := range dirs {
+		entries = append(entries, object.TreeEntry{Name: dir, Mode: filemode.Dir, Hash: r.tree(files, prefix+dir+"/")})
+	}
+	// Git orders entries by name, with directories compared as if they ended in "/"
+	key := func(e object.TreeEntry) string {
+		if e.Mode == filemode.Dir {
+			return e.Name + "/"
+		}
+		return e.Name
+	}
+	sort.Slice(entries, func(i, j int) bool { return key(entries[i]) < key(entries[j]) })
+	return r.store(&object.Tree{Entries: entries})
+}
+
+// store writes an object into the repository and returns its hash.
+func (r *Repo) store(o interface {
+	Encode(plumbing.EncodedObject) error
+}) plumbing.Hash {
+	obj := r.Repo.Storer.NewEncodedObject()
+	if err := o.Encode(obj); err != nil {
+		r.t.Fatal(err)
+	}
+	hash, err := r.Repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return hash
+}
+
+// tip returns the commit a branch points at.
+func (r *Repo) tip(branch string) (plumbing.Hash, bool) {
+	ref, err := r.Repo.Reference(plumbing.NewBranchReferenceName(branch), false)
+	if err != nil {
+		return plumbing.ZeroHash, false
+	}
+	return ref.Hash(), true
+}
+
+// Branch creates a branch at the checked out commit and checks it out.
+func (r *Repo) Branch(name string) {
+	r.t.Helper()
+	files := make(map[string]string, len(r.files[r.branch]))
+	for path, content := range r.files[r.branch] {
+		files[path] = content
+	}
+	if tip, ok := r.tip(r.branch); ok {
+		ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(name), tip)
+		if err := r.Repo.Storer.SetReference(ref); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	r.files[name] = files
+	r.Checkout(name)
+}
+
+// Checkout switches to an existing branch. Only HEAD moves: the files on
+// disk are left as they are.
+func (r *Repo) Checkout(name string) {
+	r.t.Helper()
+	if _, ok := r.files[name]; !ok {
+		r.t.Fatalf("no branch %s", name)
+	}
+	r.branch = name
+	head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName(name))
+	if err := r.Repo.Storer.SetReference(head); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// Tag creates a lightweight tag at hash.
+func (r *Repo) Tag(name string, hash plumbing.Hash) {
+	r.t.Helper()
+	if _, err := r.Repo.CreateTag(name, hash, nil); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// Lines returns n numbered lines, for files whose size matters.
+func Lines(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		b.WriteString("line " + strconv.Itoa(i) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// matrixNode is one repository or directory of the ownership matrix.
+type matrixNode struct {
+	Name     string
+	Weights  map[string]float64 // canonical_email -> Score within the node
+	Children map[string]*matrixNode
+}
+
+// matrixOptions bounds the size of the ownership matrix.
+type matrixOptions struct {
+	Depth   int // Directory levels below each repository
+	Breadth int // Children shown per node, heaviest first (0 = all)
+	Owners  int // Top owners listed per node
+}
+
+func newMatrixNode(name string) *matrixNode {
+	return &matrixNode{Name: name, Weights: make(map[string]float64), Children: make(map[string]*matrixNode)}
+}
+
+// child returns the named child, creating it if needed.
+func (n *matrixNode) child(name string) *matrixNode {
+	c, ok := n.Children[name]
+	if !ok {
+		c = newMatrixNode(name)
+		n.Children[name] = c
+	}
+	return c
+}
+
+// total is the summed score of every owner of the node.
+func (n *matrixNode) total() float64 {
+	sum := 0.0
+	for _, w := range n.Weights {
+		sum += w
+	}
+	return sum
+}
+
+// buildMatrix arranges the accumulated data as repository -> directory tree.
+// Repository nodes carry the full repository scores; directory nodes sum the
+// per-file weights below them, down to depth levels. Files deeper than that
+// count towards their ancestor at the cut, and files at a repository's root
+// go to RootFilesBucket.
+func buildMatrix(data *owner.Data, depth int) []*matrixNode {
+	repos := make(map[string]*matrixNode)
+	repo := func(name string) *matrixNode {
+		if _, ok := repos[name]; !ok {
+			repos[name] = newMatrixNode(name)
+		}
+		return repos[name]
+	}
+	for email, scores := range data.RepoScores {
+		for name, w := range scores {
+			repo(name).Weights[email] += w
+		}
+	}
+	for key, weights := range data.Files {
+		node := repo(key.Repo)
+		dirs := strings.Split(key.Path, "/")
+		dirs = dirs[:len(dirs)-1] // Drop the file name
+		if len(dirs) == 0 {
+			dirs = []string{owner.RootFilesBucket}
+		} else {
+			for i := range dirs {
+				dirs[i] += "/"
+			}
+		}
+		if len(dirs) > depth {
+			dirs = dirs[:depth]
+		}
+		for _, dir := range dirs {
+			node = node.child(dir)
+			for email, w := range weights {
+				node.Weights[email] += w
+			}
+		}
+	}
+
+	nodes := make([]*matrixNode, 0, len(repos))
+	for _, n := range repos {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
+
+// visibleChildren returns the children to display: the breadth heaviest ones
+// (all if breadth is 0) sorted by name, and how many were left out.
+func (n *matrixNode) visibleChildren(breadth int) ([]*matrixNode, int) {
+	children := make([]*matrixNode, 0, len(n.Children))
+	for _, c := range n.Children {
+		children = append(children, c)
+	}
+	hidden := 0
+	if breadth > 0 && len(children) > breadth {
+		sort.Slice(children, func(i, j int) bool {
+			if children[i].total() != children[j].total() {
+				return children[i].total() > children[j].total()
+			}
+			return children[i].Name < children[j].Name
+		})
+		hidden = len(children) - breadth
+		children = children[:breadth]
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+	return children, hidden
+}
+
+// topOwners returns the count strongest owners of a node, ties broken by email.
+func (n *matrixNode) topOwners(count int) []string {
+	emails := make([]string, 0, len(n.Weights))
+	for email := range n.Weights {
+		emails = append(emails, email)
+	}
+	sort.Slice(emails, func(i, j int) bool {
+		if n.Weights[emails[i]] != n.Weights[emails[j]] {
+			return n.Weights[emails[i]] > n.Weights[emails[j]]
+		}
+		return emails[i] < emails[j]
+	})
+	if len(emails) > count {
+		emails = emails[:count]
+	}
+	return emails
+}
+
+// topOwnersLabel formats the count strongest owners of a node as "email (score), ...".
+func (n *matrixNode) topOwnersLabel(count int) string {
+	emails := n.topOwners(count)
+	parts := make([]string, len(emails))
+	for i, email := range emails {
+		parts[i] = fmt.Sprintf("%s (%.2f)", email, n.Weights[email])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printMatrixText prints the matrix as an indented tree.
+func printMatrixText(repos []*matrixNode, mo matrixOptions) {
+	var walk func(n *matrixNode, indent string)
+	walk = func(n *matrixNode, indent string) {
+		fmt.Printf("%s%s: %s\n", indent, n.Name, n.topOwnersLabel(mo.Owners))
+		children, hidden := n.visibleChildren(mo.Breadth)
+		for _, c := range children {
+			walk(c, indent+"  ")
+		}
+		if hidden > 0 {
+			fmt.Printf("%s  ... %d more\n", indent, hidden)
+		}
+	}
+	fmt.Println("\n--- Ownership Matrix ---")
+	for _, repo := range repos {
+		walk(repo, "")
+	}
+}
+
+// printMatrixMarkdown prints the matrix as a nested Markdown list, ready for a wiki page.
+func printMatrixMarkdown(repos []*matrixNode, mo matrixOptions) {
+	var walk func(n *matrixNode, indent string, isRepo bool)
+	walk = func(n *matrixNode, indent string, isRepo bool) {
+		name := "`" + n.Name + "`"
+		if isRepo {
+			name = "**" + escapeMarkdownCell(n.Name) + "**"
+		}
+		fmt.Printf("%s- %s: %s\n", indent, name, escapeMarkdownCell(n.topOwnersLabel(mo.Owners)))
+		children, hidden := n.visibleChildren(mo.Breadth)
+		for _, c := range children {
+			walk(c, indent+"  ", false)
+		}
+		if hidden > 0 {
+			fmt.Printf("%s  - _%d more_\n", indent, hidden)
+		}
+	}
+	for _, repo := range repos {
+		walk(repo, "", true)
+	}
+}
+
+// matrixJSONNode is the JSON form of a matrixNode, bounded like the other renderings.
+type matrixJSONNode struct {
+	Name     string            `json:"name"`
+	Owners   []matrixJSONOwner `json:"owners"`
+	Children []matrixJSONNode  `json:"children,omitempty"`
+	More     int               `json:"more,omitempty"` // Children left out by --matrix-breadth
+}
+
+type matrixJSONOwner struct {
+	Email string  `json:"email"`
+	Score float64 `json:"score"`
+}
+
+// printMatrixJSON prints the matrix as a JSON array of repository trees.
+func printMatrixJSON(repos []*matrixNode, mo matrixOptions) error {
+	var convert func(n *matrixNode) matrixJSONNode
+	convert = func(n *matrixNode) matrixJSONNode {
+		node := matrixJSONNode{Name: n.Name, Owners: []matrixJSONOwner{}}
+		for _, email := range n.topOwners(mo.Owners) {
+			node.Owners = append(node.Owners, matrixJSONOwner{Email: email, Score: n.Weights[email]})
+		}
+		children, hidden := n.visibleChildren(mo.Breadth)
+		for _, c := range children {
+			node.Children = append(node.Children, convert(c))
+		}
+		node.More = hidden
+		return node
+	}
+	nodes := make([]matrixJSONNode, 0, len(repos))
+	for _, repo := range repos {
+		nodes = append(nodes, convert(repo))
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(nodes)
+}
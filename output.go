@@ -0,0 +1,431 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+	"gopkg.in/yaml.v3"
+)
+
+// outputOptions controls which optional details the renderers include.
+type outputOptions struct {
+	Sampling  bool // Scores are sampled estimates: show their intervals
+	Explain   bool // Show a score breakdown per owner (text only)
+	MultiRepo bool // Several repositories were analyzed: show each owner's home repo
+	RawCount  bool // Scores are plain commit counts (--raw-count): label them "Commits" instead of repeating the count
+
+	TopCommits []owner.CommitRecord // With Explain, the first owner's highest-weighted commit credits
+	Now        time.Time            // Reference time for the ages of TopCommits
+}
+
+// scoreLabel names the score column.
+func (o outputOptions) scoreLabel() string {
+	if o.RawCount {
+		return "Commits"
+	}
+	return "Score"
+}
+
+// formatScore formats a score for text, table and Markdown output. Raw
+// counts are whole numbers unless an explicit --bonus-per-repo scaled them.
+func (o outputOptions) formatScore(score float64) string {
+	if o.RawCount && score == math.Trunc(score) {
+		return fmt.Sprintf("%.0f", score)
+	}
+	return fmt.Sprintf("%.2f", score)
+}
+
+// scoreKey names the score field in JSON, YAML and CSV output.
+func (o outputOptions) scoreKey() string {
+	if o.RawCount {
+		return "commits"
+	}
+	return "score"
+}
+
+// escapeMarkdownCell makes a value safe to place inside a Markdown table cell.
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// printMarkdown renders the owners as a GitHub-flavored Markdown table.
+// Optional columns are added for sampled score intervals, home repos and,
+// when any owner shown merged several emails, their aliases.
+func printMarkdown(owners []owner.OwnerScore, out outputOptions) {
+	withAliases := false
+	for _, o := range owners {
+		withAliases = withAliases || len(o.AliasesUsed) > 0
+	}
+	header := "| Rank | Email | Name | " + out.scoreLabel() + " |"
+	align := "|---:|---|---|---:|"
+	if out.Sampling {
+		header += " Score Low | Score High |"
+		align += "---:|---:|"
+	}
+	header += " Repos |"
+	align += "---:|"
+	if !out.RawCount {
+		header += " Commits |"
+		align += "---:|"
+	}
+	header += " Active Days |"
+	align += "---:|"
+	if out.MultiRepo {
+		header += " Home Repo |"
+		align += "---|"
+	}
+	if withAliases {
+		header += " Aliases |"
+		align += "---|"
+	}
+	fmt.Println(header)
+	fmt.Println(align)
+
+	for i, owner := range owners {
+		email := owner.Email
+		if owner.GitHubLogin != "" {
+			email += " (@" + owner.GitHubLogin + ")"
+		}
+		row := fmt.Sprintf("| %d | %s | %s | %s |",
+			i+1,
+			escapeMarkdownCell(email),
+			escapeMarkdownCell(owner.Name),
+			out.formatScore(owner.Score))
+		if out.Sampling {
+			row += fmt.Sprintf(" %.2f | %.2f |", owner.ScoreLow, owner.ScoreHigh)
+		}
+		row += fmt.Sprintf(" %d |", owner.RepoCount)
+		if !out.RawCount {
+			row += fmt.Sprintf(" %d |", owner.CommitCount)
+		}
+		row += fmt.Sprintf(" %d |", owner.ActiveDays)
+		if out.MultiRepo {
+			row += fmt.Sprintf(" %s |", escapeMarkdownCell(owner.HomeRepo))
+		}
+		if withAliases {
+			row += fmt.Sprintf(" %s |", escapeMarkdownCell(strings.Join(owner.AliasesUsed, ", ")))
+		}
+		fmt.Println(row)
+	}
+}
+
+// printText prints the owners as a numbered list, one line per owner.
+// With Explain set, each owner is followed by an indented breakdown line,
+// and the first one by its TopCommits.
+func printText(owners []owner.OwnerScore, out outputOptions) {
+	for i, owner := range owners {
+		aliasInfo := ""
+		if len(owner.AliasesUsed) > 0 {
+			// Add alias information if it exists for this owner
+			aliasInfo = fmt.Sprintf(" (aliases: %s)", strings.Join(owner.AliasesUsed, ", "))
+		}
+		marginInfo := ""
+		if out.Sampling {
+			marginInfo = fmt.Sprintf(" ±%.2f", owner.ScoreHigh-owner.Score)
+		}
+		homeInfo := ""
+		if out.MultiRepo {
+			homeInfo = fmt.Sprintf(", Home: %s", owner.HomeRepo)
+		}
+		email := owner.Email
+		if owner.GitHubLogin != "" {
+			email += " (@" + owner.GitHubLogin + ")"
+		}
+		commitInfo := fmt.Sprintf(", Commits: %d", owner.CommitCount)
+		if out.RawCount {
+			commitInfo = ""
+		}
+		fmt.Printf("%d. %s (%s: %s%s, Repos: %d%s, Active days: %d%s)%s\n",
+			i+1,
+			email,
+			out.scoreLabel(),
+			out.formatScore(owner.Score),
+			marginInfo,
+			owner.RepoCount,
+			commitInfo,
+			owner.ActiveDays,
+			homeInfo,
+			aliasInfo)
+		if out.Explain {
+			fmt.Printf("   raw score %.2f × bonus factor %.3f (repos: %d) = %.2f; commits: %d, ticket refs: %d\n",
+				owner.RawScore,
+				owner.BonusFactor,
+				owner.RepoCount,
+				owner.RawScore*owner.BonusFactor,
+				owner.CommitCount,
+				owner.TicketRefs)
+		}
+		if out.Explain && i == 0 {
+			for _, c := range out.TopCommits {
+				fmt.Printf("     %.12s %s %s (%.0fd ago): %.4f\n",
+					c.Hash,
+					c.Repo,
+					c.When.Format(time.DateOnly),
+					max(0, out.Now.Sub(c.When).Hours()/24),
+					c.Weight)
+			}
+		}
+	}
+}
+
+// topCommits returns the count highest-weighted credits of email in log,
+// ties broken by recency.
+func topCommits(log []owner.CommitRecord, email string, count int) []owner.CommitRecord {
+	var credits []owner.CommitRecord
+	for _, c := range log {
+		if c.Email == email {
+			credits = append(credits, c)
+		}
+	}
+	sort.Slice(credits, func(i, j int) bool {
+		if credits[i].Weight != credits[j].Weight {
+			return credits[i].Weight > credits[j].Weight
+		}
+		return credits[i].When.After(credits[j].When)
+	})
+	if len(credits) > count {
+		credits = credits[:count]
+	}
+	return credits
+}
+
+// tableMaxAliases is the longest alias list printTable shows in full;
+// longer ones are summarized as "N aliases".
+const tableMaxAliases = 2
+
+// printTable prints the owners as an aligned text table (--format=table).
+func printTable(owners []owner.OwnerScore, out outputOptions) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if out.RawCount {
+		fmt.Fprintln(w, "Rank\tEmail\tCommits\tRepos\tDays\tAliases")
+	} else {
+		fmt.Fprintln(w, "Rank\tEmail\tScore\tRepos\tCommits\tDays\tAliases")
+	}
+	for i, owner := range owners {
+		email := owner.Email
+		if owner.GitHubLogin != "" {
+			email += " (@" + owner.GitHubLogin + ")"
+		}
+		score := out.formatScore(owner.Score)
+		if out.Sampling {
+			score += fmt.Sprintf(" ±%.2f", owner.ScoreHigh-owner.Score)
+		}
+		aliases := strings.Join(owner.AliasesUsed, ", ")
+		if len(owner.AliasesUsed) > tableMaxAliases {
+			aliases = fmt.Sprintf("%d aliases", len(owner.AliasesUsed))
+		}
+		if out.RawCount {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\n", i+1, email, score, owner.RepoCount, owner.ActiveDays, aliases)
+		} else {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%d\t%s\n", i+1, email, score, owner.RepoCount, owner.CommitCount, owner.ActiveDays, aliases)
+		}
+	}
+	w.Flush()
+}
+
+// printCompact prints one "email score" pair per line, in ranking order, with
+// no headers, for dashboards and shell scripts.
+func printCompact(owners []owner.OwnerScore) {
+	for _, owner := range owners {
+		fmt.Printf("%s %.4f\n", owner.Email, owner.Score)
+	}
+}
+
+// rawCountOwner is an owner in JSON output with --raw-count, where the score
+// is a commit count and is named "commits" instead of "score".
+type rawCountOwner struct {
+	owner.OwnerScore
+	Score   *float64 `json:"score,omitempty"` // Always nil: hides OwnerScore.Score
+	Commits float64  `json:"commits"`
+}
+
+// jsonOwners returns the owners to encode as JSON, in ranking order.
+func (o outputOptions) jsonOwners(owners []owner.OwnerScore) any {
+	if !o.RawCount {
+		if owners == nil {
+			owners = []owner.OwnerScore{} // An empty ranking is [], not null
+		}
+		return owners
+	}
+	counted := make([]rawCountOwner, len(owners))
+	for i, ow := range owners {
+		counted[i] = rawCountOwner{OwnerScore: ow, Commits: ow.Score}
+	}
+	return counted
+}
+
+// printJSON writes the ranking to stdout as a JSON array, in ranking order.
+func printJSON(owners []owner.OwnerScore, out outputOptions) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out.jsonOwners(owners))
+}
+
+// jsonReport is the document printed by --format=json --json-envelope.
+type jsonReport struct {
+	Parameters parameterObject     `json:"parameters"`
+	Owners     any                 `json:"owners"`
+	Skipped    []owner.SkippedRepo `json:"skipped"`
+}
+
+// printJSONEnvelope writes the run parameters, the ranking in ranking order
+// and the repositories skipped by the scan to stdout as a JSON object, so a
+// partial result can be told apart from a complete one.
+func printJSONEnvelope(owners []owner.OwnerScore, skipped []owner.SkippedRepo, params []parameter, out outputOptions) error {
+	if skipped == nil {
+		skipped = []owner.SkippedRepo{}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jsonReport{parameterObject(params), out.jsonOwners(owners), skipped})
+}
+
+// printYAML writes the run parameters, in order, and the ranking to stdout as
+// a YAML document. Owners have the same fields as in JSON output.
+func printYAML(owners []owner.OwnerScore, params []parameter, out outputOptions) error {
+	if owners == nil {
+		owners = []owner.OwnerScore{}
+	}
+	var list yaml.Node
+	if err := list.Encode(owners); err != nil {
+		return err
+	}
+	for _, o := range list.Content {
+		for i := 0; i < len(o.Content); i += 2 {
+			if o.Content[i].Value == "score" {
+				o.Content[i].Value = out.scoreKey()
+			}
+		}
+	}
+	header := yaml.Node{Kind: yaml.MappingNode}
+	for _, p := range mergeParameters(params) {
+		header.Content = append(header.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: p.Key},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: p.Value})
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(struct {
+		Parameters *yaml.Node `yaml:"parameters"`
+		Owners     *yaml.Node `yaml:"owners"`
+	}{&header, &list}); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// printCSV writes the ranking to stdout as CSV with a header row. Aliases are
+// joined with ";" and times are RFC 3339 in UTC.
+func printCSV(owners []owner.OwnerScore, out outputOptions) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"rank", "email", "name", out.scoreKey(), "score_low", "score_high", "raw_score", "bonus_factor", "repo_count", "commit_count", "active_days", "ticket_refs", "home_repo", "last_active", "aliases_used"})
+	float := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	for i, o := range owners {
+		w.Write([]string{
+			strconv.Itoa(i + 1), o.Email, o.Name,
+			float(o.Score), float(o.ScoreLow), float(o.ScoreHigh), float(o.RawScore), float(o.BonusFactor),
+			strconv.Itoa(o.RepoCount), strconv.Itoa(o.CommitCount), strconv.Itoa(o.ActiveDays), strconv.Itoa(o.TicketRefs),
+			o.HomeRepo, o.LastActive.UTC().Format(time.RFC3339), strings.Join(o.AliasesUsed, ";"),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// printInvalidEmails lists the malformed emails seen during the scan with the
+// number of commits credited to each, most frequent first.
+func printInvalidEmails(w io.Writer, invalid map[string]int) {
+	if len(invalid) == 0 {
+		fmt.Fprintln(w, "No invalid author emails found.")
+		return
+	}
+	emails := make([]string, 0, len(invalid))
+	for email := range invalid {
+		emails = append(emails, email)
+	}
+	sort.Slice(emails, func(i, j int) bool {
+		if invalid[emails[i]] != invalid[emails[j]] {
+			return invalid[emails[i]] > invalid[emails[j]]
+		}
+		return emails[i] < emails[j]
+	})
+	fmt.Fprintf(w, "Invalid author emails (%d):\n", len(emails))
+	for _, email := range emails {
+		fmt.Fprintf(w, "  %q: %d commits\n", email, invalid[email])
+	}
+}
+
+// pruneReason describes the ranking filters that were applied, for the
+// "Pruned N owners ..." notes.
+func pruneReason(rank owner.RankOptions, pruneStale string) string {
+	var reasons []string
+	if rank.PruneStale > 0 {
+		reasons = append(reasons, "with no commits in the last "+pruneStale)
+	}
+	if rank.MinScore > 0 {
+		reasons = append(reasons, fmt.Sprintf("scoring below %g", rank.MinScore))
+	}
+	if rank.MinRepos > 0 {
+		reasons = append(reasons, fmt.Sprintf("in fewer than %d repositories", rank.MinRepos))
+	}
+	return strings.Join(reasons, " or ")
+}
+
+// redirectOutput sends everything printed to stdout to path instead, creating
+// or truncating it. An empty path or "-" keeps stdout. Diagnostics stay on
+// stderr. Output goes through a pipe so write errors, which fmt.Print*
+// discards, are caught; they are reported at exit with exitUsage, so this
+// must be registered before any other cleanup.
+func redirectOutput(path string) error {
+	if path == "" || path == "-" {
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	copied := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(f, r)
+		r.Close()
+		copied <- err
+	}()
+	stdout := os.Stdout
+	os.Stdout = w
+	atExit(func() {
+		os.Stdout = stdout
+		w.Close()
+		err := <-copied
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing --output %s: %v\n", path, err)
+			os.Exit(exitUsage)
+		}
+	})
+	return nil
+}
+
+// isTerminal reports whether f is a character device such as a terminal, so
+// redrawn progress lines are not written into logs or pipes.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
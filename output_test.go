@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mateobur/gitowner/internal/testrepo"
+	"github.com/mateobur/gitowner/pkg/owner"
+	"gopkg.in/yaml.v3"
+)
+
+// decodeJSON reads the owners printed by --format=json, as a consumer would.
+func decodeJSON(t *testing.T, out string) []owner.OwnerScore {
+	t.Helper()
+	var owners []owner.OwnerScore
+	dec := json.NewDecoder(strings.NewReader(out))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&owners); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	return owners
+}
+
+func TestJSONOutput(t *testing.T) {
+	r := ownersRepo(t)
+	owners := decodeJSON(t, mustRun(t, "--format=json", "--count=2", r.Dir))
+
+	if len(owners) != 2 {
+		t.Fatalf("got %d owners, want 2 (--count)", len(owners))
+	}
+	bob := owners[0]
+	if bob.Email != "bob@example.com" || bob.Name != "bob" || bob.RepoCount != 1 || bob.CommitCount != 2 {
+		t.Errorf("unexpected first owner %+v", bob)
+	}
+	if bob.Score <= owners[1].Score {
+		t.Errorf("owners not sorted by score: %g then %g", bob.Score, owners[1].Score)
+	}
+	if bob.RawScore != bob.Score || len(bob.AliasesUsed) != 0 {
+		t.Errorf("single-repo owner: raw score %g, score %g, aliases %v", bob.RawScore, bob.Score, bob.AliasesUsed)
+	}
+}
+
+func TestYAMLOutput(t *testing.T) {
+	r := ownersRepo(t)
+	out := mustRun(t, "--format=yaml", r.Dir)
+	var doc struct {
+		Parameters map[string]string  `yaml:"parameters"`
+		Owners     []owner.OwnerScore `yaml:"owners"`
+	}
+	dec := yaml.NewDecoder(strings.NewReader(out))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, out)
+	}
+	want := decodeJSON(t, mustRun(t, "--format=json", r.Dir))
+	if !reflect.DeepEqual(doc.Owners, want) {
+		t.Errorf("YAML owners differ from the JSON ranking:\ngot  %+v\nwant %+v", doc.Owners, want)
+	}
+	if doc.Parameters["repositories"] != "1" || doc.Parameters["tau_days"] != "365" {
+		t.Errorf("got parameters %v, want the run's settings", doc.Parameters)
+	}
+
+	if err := yaml.Unmarshal([]byte(mustRun(t, "--format=yaml", "--count=1", r.Dir)), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Owners) != 1 || doc.Owners[0].Email != "bob@example.com" {
+		t.Errorf("--count=1: got %+v, want only bob", doc.Owners)
+	}
+}
+
+func TestCSVOutput(t *testing.T) {
+	r := ownersRepo(t)
+	records, err := csv.NewReader(strings.NewReader(mustRun(t, "--format=csv", r.Dir))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("got %d records, want a header and 3 owners", len(records))
+	}
+	header := records[0]
+	if header[0] != "rank" || header[1] != "email" || header[3] != "score" {
+		t.Errorf("unexpected header %v", header)
+	}
+	if records[1][1] != "bob@example.com" || records[3][1] != "carol@example.com" {
+		t.Errorf("unexpected ranking %v", records[1:])
+	}
+}
+
+func TestDiagnosticsGoToStderr(t *testing.T) {
+	r := ownersRepo(t)
+	res := run(t, "--format=json", r.Dir)
+	if !strings.Contains(res.Stderr, "Processing repository") {
+		t.Errorf("progress missing from stderr: %q", res.Stderr)
+	}
+	if strings.Contains(res.Stdout, "Processing repository") {
+		t.Error("progress written to stdout")
+	}
+}
+
+func TestOutputFile(t *testing.T) {
+	r := ownersRepo(t)
+	file := filepath.Join(t.TempDir(), "owners.json")
+	if out := mustRun(t, "--format=json", "--output="+file, r.Dir); out != "" {
+		t.Errorf("report also written to stdout: %q", out)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owners := decodeJSON(t, string(content)); len(owners) != 3 || owners[0].Email != "bob@example.com" {
+		t.Errorf("unexpected report in %s: %+v", file, owners)
+	}
+
+	res := run(t, "--output="+filepath.Join(t.TempDir(), "missing", "owners.json"), r.Dir)
+	if res.Code != exitUsage || !strings.Contains(res.Stderr, "--output") {
+		t.Errorf("unwritable --output: exit code %d, stderr %q", res.Code, res.Stderr)
+	}
+}
+
+func TestQuietAndVerbose(t *testing.T) {
+	r := ownersRepo(t)
+	quiet := run(t, "--quiet", r.Dir)
+	if quiet.Code != 0 || quiet.Stderr != "" {
+		t.Errorf("--quiet: exit code %d, stderr %q", quiet.Code, quiet.Stderr)
+	}
+	verbose := run(t, "--verbose", r.Dir)
+	if normal := run(t, r.Dir); quiet.Stdout != normal.Stdout || verbose.Stdout != normal.Stdout {
+		t.Error("the verbosity changed the report")
+	}
+	// One debug line per scored commit, which the default level leaves out
+	if got := strings.Count(verbose.Stderr, r.Dir+" "); got < 6 {
+		t.Errorf("--verbose: got %d commit lines, want at least 6:\n%s", got, verbose.Stderr)
+	}
+	if res := run(t, "--quiet", "--verbose", r.Dir); res.Code != exitUsage {
+		t.Errorf("--quiet --verbose: exit code %d, want %d", res.Code, exitUsage)
+	}
+}
+
+func TestProgressNeedsTerminal(t *testing.T) {
+	r := ownersRepo(t)
+	res := run(t, "--progress", r.Dir, r.Dir)
+	if res.Code != 0 || strings.Contains(res.Stderr, "Repositories done") || strings.Contains(res.Stderr, "\r") {
+		t.Errorf("progress shown on a non-terminal stderr: %q", res.Stderr)
+	}
+	if normal := run(t, r.Dir, r.Dir); res.Stdout != normal.Stdout {
+		t.Error("--progress changed the report")
+	}
+	for _, args := range [][]string{{"--full-blame"}, {"--full-blame", "--progress"}} {
+		res := run(t, append(args, r.Dir)...)
+		if res.Code != 0 || strings.Contains(res.Stderr, "Blaming files") || strings.Contains(res.Stderr, "\r") {
+			t.Errorf("%v: blame progress shown on a non-terminal stderr: %q", args, res.Stderr)
+		}
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Error("isTerminal reported a regular file as a terminal")
+	}
+}
+
+func TestTableOutput(t *testing.T) {
+	r := ownersRepo(t)
+	for _, alias := range []string{"al@example.com", "ali@example.com", "alice@old.example.com"} {
+		r.Commit(alias, testrepo.DaysAgo(500), nil)
+	}
+	aliases := writeConfig(t, `[aliases]
+"alice@example.com" = ["al@example.com", "ali@example.com", "alice@old.example.com"]
+"carol@example.com" = ["caroline@example.com"]
+`)
+	out := mustRun(t, "--format=table", "--count=2", "--aliases-file="+aliases, r.Dir)
+	_, table, _ := strings.Cut(out, "\n\nRank ")
+	table, _, _ = strings.Cut("Rank "+table, "\n\n") // The parameters follow
+	lines := strings.Split(table, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want a header and 2 rows (--count):\n%s", len(lines), out)
+	}
+	// Every column starts at the same offset on every line
+	header := lines[0]
+	for _, column := range []string{"Email", "Score", "Repos", "Commits", "Days"} {
+		offset := strings.Index(header, column)
+		for _, line := range lines[1:] {
+			if offset < 0 || offset >= len(line) || line[offset] == ' ' || line[offset-1] != ' ' {
+				t.Errorf("column %s not aligned at %d:\n%s", column, offset, out)
+				break
+			}
+		}
+	}
+	if !strings.HasPrefix(lines[1], "1     alice@example.com ") || !strings.HasSuffix(lines[1], "  3 aliases") {
+		t.Errorf("got %q, want alice with 3 aliases counted", lines[1])
+	}
+}
+
+// markdownCells splits a Markdown table row into its cells, keeping escaped pipes.
+func markdownCells(row string) []string {
+	row = strings.ReplaceAll(row, `\|`, "\x00")
+	cells := strings.Split(strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|"), "|")
+	for i, cell := range cells {
+		cells[i] = strings.TrimSpace(strings.ReplaceAll(cell, "\x00", `\|`))
+	}
+	return cells
+}
+
+func TestMarkdownOutput(t *testing.T) {
+	r := ownersRepo(t)
+	when := testrepo.DaysAgo(0)
+	r.CommitWith(&object.Signature{Name: "Pipe | Name", Email: "pipe@example.com", When: when}, testrepo.Sig("pipe@example.com", when), "", nil)
+	out := mustRun(t, "--format=markdown", "--count=3", r.Dir)
+
+	var rows []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "|") {
+			rows = append(rows, line)
+		}
+	}
+	if len(rows) != 5 {
+		t.Fatalf("got %d table lines, want a header, a delimiter and 3 rows (--count):\n%s", len(rows), out)
+	}
+	header := markdownCells(rows[0])
+	if header[0] != "Rank" || header[1] != "Email" || header[3] != "Score" {
+		t.Errorf("unexpected header %v", header)
+	}
+	for i, cell := range markdownCells(rows[1]) {
+		if strings.Trim(cell, ":-") != "" || !strings.Contains(cell, "---") {
+			t.Errorf("delimiter cell %d is %q", i, cell)
+		}
+	}
+	for _, row := range rows[1:] {
+		if cells := markdownCells(row); len(cells) != len(header) {
+			t.Errorf("row %q has %d cells, want %d", row, len(cells), len(header))
+		}
+	}
+	if cells := markdownCells(rows[4]); cells[1] != "pipe@example.com" || cells[2] != `Pipe \| Name` {
+		t.Errorf("got third row %v, want pipe@example.com with the pipe in the name escaped", cells)
+	}
+}
+
+func TestSkippedReposInJSON(t *testing.T) {
+	r := ownersRepo(t)
+	bogus := filepath.Join(t.TempDir(), "not-a-repo")
+	if err := os.Mkdir(bogus, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, strict := range []bool{false, true} {
+		args := []string{"--format=json", "--json-envelope", r.Dir, bogus}
+		if strict {
+			args = append([]string{"--strict"}, args...)
+		}
+		res := run(t, args...)
+		if res.Code != exitPartialFailure {
+			t.Errorf("strict %v: exit code %d, want %d", strict, res.Code, exitPartialFailure)
+		}
+		var doc struct {
+			Parameters map[string]string   `json:"parameters"`
+			Owners     []owner.OwnerScore  `json:"owners"`
+			Skipped    []owner.SkippedRepo `json:"skipped"`
+		}
+		dec := json.NewDecoder(strings.NewReader(res.Stdout))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&doc); err != nil {
+			t.Fatalf("strict %v: %v\n%s", strict, err, res.Stdout)
+		}
+		if doc.Parameters["repositories"] != "2" {
+			t.Errorf("strict %v: got parameters %v, want 2 repositories", strict, doc.Parameters)
+		}
+		if len(doc.Owners) != 3 || doc.Owners[0].Email != "bob@example.com" {
+			t.Errorf("strict %v: got owners %+v, want the valid repository's", strict, doc.Owners)
+		}
+		if len(doc.Skipped) != 1 || doc.Skipped[0].Repo != bogus || !strings.Contains(doc.Skipped[0].Error, "repository not found") {
+			t.Errorf("strict %v: got skipped %+v, want %s", strict, doc.Skipped, bogus)
+		}
+	}
+}
+
+func TestParametersOut(t *testing.T) {
+	r := ownersRepo(t)
+	path := filepath.Join(t.TempDir(), "params.json")
+	out := mustRun(t, "--format=csv", "--parameters-out="+path, "--exclude-email=x@example.com", "--exclude-email=y@example.com", r.Dir)
+	if strings.Contains(out, "#") {
+		t.Errorf("CSV output carries the parameters:\n%s", out)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var params map[string]string
+	if err := json.Unmarshal(raw, &params); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, raw)
+	}
+	if params["tau_days"] != "365" || params["repositories"] != "1" || params["exclude_email"] != "x@example.com, y@example.com" {
+		t.Errorf("got parameters %v", params)
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// parameter is one entry of the effective scoring configuration reported with the results.
+type parameter struct {
+	Key   string
+	Value string
+}
+
+// scoringParameters describes the effective configuration of a scan: the
+// decay settings, the reference time and every active filter or weighting
+// option, so a report can be reproduced later. Options left at their
+// defaults are omitted.
+func scoringParameters(opts owner.ScanOptions) []parameter {
+	params := []parameter{
+		{"tau_days", fmt.Sprintf("%g", opts.Tau)},
+		{"decay", opts.Decay},
+		{"weight_by", opts.WeightBy},
+		{"reference_time", opts.Now.UTC().Format(time.RFC3339)},
+		{"identity", opts.Identity},
+	}
+	add := func(key, format string, args ...any) {
+		params = append(params, parameter{key, fmt.Sprintf(format, args...)})
+	}
+
+	if opts.WeightFloor > 0 {
+		params[1].Value = fmt.Sprintf("%s, floor %g", opts.Decay, opts.WeightFloor)
+	}
+	if opts.FullBlame {
+		params[2].Value = "surviving lines (full blame)"
+		if opts.WeightBy == owner.WeightByRegions {
+			params[2].Value = "contiguous regions (full blame)"
+		}
+	}
+	if opts.Identity == owner.IdentityBoth {
+		add("committer_weight", "%g", opts.CommitterWeight)
+	}
+	if opts.Identity != owner.IdentityCommitter && opts.CoauthorWeight != 1 {
+		add("coauthor_weight", "%g", opts.CoauthorWeight)
+	}
+	if len(opts.RepoTau) > 0 {
+		add("repo_tau_days", "%s", formatRepoValues(opts.RepoTau))
+	}
+	if opts.RawCount {
+		add("raw_count", "true")
+	}
+	if len(opts.RepoWeight) > 0 {
+		add("repo_weight", "%s", formatRepoValues(opts.RepoWeight))
+	}
+	if opts.VerifySignatures {
+		add("signed_only", "verified")
+	} else if opts.SignedOnly {
+		add("signed_only", "true")
+	}
+	if opts.OriginBonus > 0 {
+		add("origin_bonus", "%g", opts.OriginBonus)
+	}
+	if opts.DropFutureDated {
+		add("drop_future_after_hours", "%g", opts.MaxFutureSkew.Hours())
+	}
+	if len(opts.Extensions) > 0 {
+		add("ext", "%s", strings.Join(opts.Extensions, ", "))
+	}
+	if opts.ScaleByExtension {
+		add("ext_scale", "true")
+	}
+	if len(opts.IgnorePaths) > 0 {
+		add("ignore_paths", "%s", strings.Join(opts.IgnorePaths, ", "))
+	}
+	if !opts.Since.IsZero() {
+		add("since", "%s", opts.Since.UTC().Format(time.RFC3339))
+	}
+	if !opts.Until.IsZero() {
+		add("until", "%s", opts.Until.UTC().Format(time.RFC3339))
+	}
+	for _, r := range opts.ExcludeRanges {
+		add("exclude_date_range", "%s..%s", r.Start.UTC().Format(time.RFC3339), r.End.UTC().Format(time.RFC3339))
+	}
+	if len(opts.PathPrefixes) > 0 {
+		add("paths", "%s", strings.Join(opts.PathPrefixes, ", "))
+	}
+	if opts.SampleRate < 1 {
+		add("sample_rate", "%g", opts.SampleRate)
+	}
+	add("seed", "%d", opts.Seed)
+	if opts.TicketBonus > 0 {
+		add("ticket_bonus", "%g", opts.TicketBonus)
+		add("ticket_regex", "%s", opts.TicketPattern)
+	}
+	if opts.DedupAcrossRepos {
+		add("dedup_across_repos", "true")
+	}
+	if opts.DiscountReverts {
+		add("discount_reverts", "true")
+		add("revert_weight", "%g", opts.RevertWeight)
+	}
+	if opts.MaintenanceBonus > 0 {
+		add("maintenance_bonus", "%g", opts.MaintenanceBonus)
+	}
+	if opts.ReleaseBonus > 0 {
+		add("release_proximity_bonus", "%g", opts.ReleaseBonus)
+		add("release_window_days", "%g", opts.ReleaseWindow.Hours()/24)
+	}
+	if opts.SignedBonus > 0 {
+		add("signed_bonus", "%g", opts.SignedBonus)
+	}
+	if opts.Candidates != nil {
+		add("top_k_precise", "%d", len(opts.Candidates))
+	}
+	if opts.WeightByLines {
+		add("weight_by_lines", "true")
+	}
+	if opts.Dependents != nil {
+		add("blast_radius_files", "%d", len(opts.Dependents))
+	}
+	if opts.InvalidEmails != owner.InvalidEmailsKeep {
+		add("invalid_emails", "%s", opts.InvalidEmails)
+	}
+	if len(opts.NormalizeDomains) > 0 {
+		domains := make([]string, 0, len(opts.NormalizeDomains))
+		for domain := range opts.NormalizeDomains {
+			domains = append(domains, domain)
+		}
+		sort.Strings(domains)
+		add("normalize_domains", "%s", strings.Join(domains, ","))
+	}
+	excluded := make([]string, 0, len(opts.ExcludeEmails))
+	for email := range opts.ExcludeEmails {
+		excluded = append(excluded, email)
+	}
+	sort.Strings(excluded)
+	for _, email := range excluded {
+		add("exclude_email", "%s", email)
+	}
+	for _, re := range opts.ExcludePatterns {
+		add("exclude_email_regex", "%s", strings.TrimPrefix(re.String(), "(?i)"))
+	}
+	for _, re := range opts.ExcludeMessages {
+		add("exclude_message_regex", "%s", strings.TrimPrefix(re.String(), "(?i)"))
+	}
+	if opts.RenameScore > 0 {
+		add("follow_renames", "%d%%", opts.RenameScore)
+	}
+	if opts.ExcludeBots {
+		add("exclude_bots", "true")
+	}
+	if opts.Ref != "" {
+		add("ref", "%s", opts.Ref)
+	}
+	if opts.ReleasedOnly {
+		add("released_only", "true")
+	}
+	if opts.AllRefs {
+		add("all_refs", "true")
+	} else if opts.AllBranches {
+		add("all_branches", "true")
+	}
+	if opts.Strict {
+		add("strict", "true")
+	}
+	return params
+}
+
+// formatRepoValues renders per-repository overrides as "repo=value" pairs
+// sorted by repository.
+func formatRepoValues(values map[string]float64) string {
+	repos := make([]string, 0, len(values))
+	for repo := range values {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	pairs := make([]string, len(repos))
+	for i, repo := range repos {
+		pairs[i] = fmt.Sprintf("%s=%g", repo, values[repo])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// runParameters extends scoringParameters with the settings applied after
+// scanning (bonus, pruning, aliases, baseline).
+func runParameters(opts owner.ScanOptions, rank owner.RankOptions, aliasesFile string, mailmaps []string, mergeByName bool, relativeTo string) []parameter {
+	params := scoringParameters(opts)
+	params = append(params, parameter{"bonus_per_repo", fmt.Sprintf("%g", rank.BonusPerRepo)})
+	if len(rank.RepoBonus) > 0 {
+		params = append(params, parameter{"repo_bonus_per_repo", formatRepoValues(rank.RepoBonus)})
+	}
+	if rank.PruneStale > 0 {
+		params = append(params, parameter{"prune_stale_days", fmt.Sprintf("%g", rank.PruneStale.Hours()/24)})
+	}
+	if rank.SortBy != "" && rank.SortBy != owner.SortScore {
+		params = append(params, parameter{"sort", rank.SortBy})
+	}
+	if rank.MinScore > 0 {
+		params = append(params, parameter{"min_score", fmt.Sprintf("%g", rank.MinScore)})
+	}
+	if rank.MinRepos > 0 {
+		params = append(params, parameter{"min_repos", fmt.Sprintf("%d", rank.MinRepos)})
+	}
+	if aliasesFile != "" {
+		params = append(params, parameter{"aliases_file", aliasesFile})
+	}
+	if len(mailmaps) > 0 {
+		params = append(params, parameter{"mailmap", strings.Join(mailmaps, ", ")})
+	}
+	if mergeByName {
+		params = append(params, parameter{"merge_by_name", "true"})
+	}
+	if relativeTo != "" {
+		params = append(params, parameter{"relative_to", relativeTo})
+	}
+	return params
+}
+
+// printParametersText prints the parameters as a text-mode footer.
+func printParametersText(params []parameter) {
+	fmt.Println("\n--- Parameters ---")
+	for _, p := range params {
+		fmt.Printf("%s: %s\n", p.Key, p.Value)
+	}
+}
+
+// mergeParameters returns params with repeated keys (such as exclude_email)
+// merged into their first entry, values joined by ", ", for formats whose
+// keys must be unique.
+func mergeParameters(params []parameter) []parameter {
+	merged := make([]parameter, 0, len(params))
+	index := make(map[string]int, len(params))
+	for _, p := range params {
+		if i, ok := index[p.Key]; ok {
+			merged[i].Value += ", " + p.Value
+			continue
+		}
+		index[p.Key] = len(merged)
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+// parameterObject marshals parameters as a JSON object with string values,
+// keeping their order.
+type parameterObject []parameter
+
+func (o parameterObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, p := range mergeParameters(o) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(p.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeParameters writes params to path as an indented JSON object, for
+// --parameters-out.
+func writeParameters(path string, params []parameter) error {
+	out, err := json.MarshalIndent(parameterObject(params), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(out, '\n'), 0o644)
+}
+
+// printParametersMarkdown prints the parameters as a Markdown footnote list.
+func printParametersMarkdown(params []parameter) {
+	fmt.Println("\n**Parameters**")
+	fmt.Println()
+	for _, p := range params {
+		fmt.Printf("- `%s`: %s\n", p.Key, escapeMarkdownCell(p.Value))
+	}
+}
+
+// printParametersDot prints the parameters as comments inside a DOT graph.
+func printParametersDot(params []parameter) {
+	for _, p := range params {
+		fmt.Printf("  // %s: %s\n", p.Key, strings.ReplaceAll(p.Value, "\n", " "))
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestExcludePath(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("gen@example.com", testrepo.DaysAgo(1), map[string]string{"gen/a.pb.go": testrepo.Lines(100)})
+	r.Commit("mixed@example.com", testrepo.DaysAgo(1), map[string]string{"gen/b.pb.go": testrepo.Lines(100), "main.go": testrepo.Lines(10)})
+	r.Commit("plain@example.com", testrepo.DaysAgo(1), map[string]string{"util.go": testrepo.Lines(10)})
+	r.Commit("svcgen@example.com", testrepo.DaysAgo(1), map[string]string{"svc/gen/x.go": "x\n"})
+	r.Commit("svc@example.com", testrepo.DaysAgo(1), map[string]string{"svc/api.go": "api\n"})
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		// Like a .gitignore entry, gen/ excludes gen directories at any depth
+		{[]string{"--exclude-path=gen/"}, []string{"mixed@example.com", "plain@example.com", "svc@example.com"}},
+		{[]string{"--exclude-path=/gen/"}, []string{"mixed@example.com", "plain@example.com", "svc@example.com", "svcgen@example.com"}},
+		{[]string{"--path=svc/", "--exclude-path=svc/gen/"}, []string{"svc@example.com"}},
+	}
+	for _, tt := range tests {
+		owners := decodeJSON(t, mustRun(t, append(append([]string{"--format=json", "--sort=email"}, tt.args...), r.Dir)...))
+		var got []string
+		for _, o := range owners {
+			got = append(got, o.Email)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: got %v, want %v", tt.args, got, tt.want)
+		}
+	}
+
+	// With --weight-by-lines the excluded lines do not count either
+	owners := decodeJSON(t, mustRun(t, "--format=json", "--weight-by-lines", "--exclude-path=gen/", r.Dir))
+	scores := make(map[string]float64)
+	for _, o := range owners {
+		scores[o.Email] = o.Score
+	}
+	if !near(scores["mixed@example.com"], scores["plain@example.com"]) {
+		t.Errorf("mixed commit scored %g, want %g like a 10-line commit", scores["mixed@example.com"], scores["plain@example.com"])
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// repoRanking is the ranking of owners within a single repository.
+type repoRanking struct {
+	Repo   string
+	Owners []repoOwner
+}
+
+// repoOwner is one owner's decayed score within a repository.
+type repoOwner struct {
+	Email string
+	Score float64
+}
+
+// repoRankings ranks the owners of every repository by their decayed score
+// within it, keeping at most count per repository. Only owners present in the
+// overall ranking are listed, so pruned owners stay out.
+func repoRankings(data *owner.Data, ranked []owner.OwnerScore, count int) []repoRanking {
+	kept := make(map[string]struct{}, len(ranked))
+	for _, o := range ranked {
+		kept[o.Email] = struct{}{}
+	}
+	byRepo := make(map[string][]repoOwner)
+	for email, repos := range data.RepoScores {
+		if _, ok := kept[email]; !ok {
+			continue
+		}
+		for repo, score := range repos {
+			byRepo[repo] = append(byRepo[repo], repoOwner{Email: email, Score: score})
+		}
+	}
+	rankings := make([]repoRanking, 0, len(byRepo))
+	for repo, owners := range byRepo {
+		sort.Slice(owners, func(i, j int) bool {
+			if owners[i].Score != owners[j].Score {
+				return owners[i].Score > owners[j].Score
+			}
+			return owners[i].Email < owners[j].Email
+		})
+		if len(owners) > count {
+			owners = owners[:count]
+		}
+		rankings = append(rankings, repoRanking{Repo: repo, Owners: owners})
+	}
+	sort.Slice(rankings, func(i, j int) bool { return rankings[i].Repo < rankings[j].Repo })
+	return rankings
+}
+
+// printRepoRankingsText prints one numbered ranking per repository.
+func printRepoRankingsText(rankings []repoRanking) {
+	for _, r := range rankings {
+		fmt.Printf("\n--- Top Likely Owners of %s ---\n", r.Repo)
+		for i, o := range r.Owners {
+			fmt.Printf("%d. %s (Score: %.2f)\n", i+1, o.Email, o.Score)
+		}
+	}
+}
+
+// printRepoRankingsMarkdown prints one table per repository under its own heading.
+func printRepoRankingsMarkdown(rankings []repoRanking) {
+	for _, r := range rankings {
+		fmt.Printf("\n### %s\n\n", escapeMarkdownCell(r.Repo))
+		fmt.Println("| Rank | Email | Score |")
+		fmt.Println("|---:|---|---:|")
+		for i, o := range r.Owners {
+			fmt.Printf("| %d | %s | %.2f |\n", i+1, escapeMarkdownCell(o.Email), o.Score)
+		}
+	}
+}
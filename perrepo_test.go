@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestPerRepoRankings(t *testing.T) {
+	a := testrepo.New(t)
+	a.Commit("alice@example.com", testrepo.DaysAgo(2), nil)
+	a.Commit("alice@example.com", testrepo.DaysAgo(1), nil)
+	a.Commit("bob@example.com", testrepo.DaysAgo(1), nil)
+	b := testrepo.New(t)
+	b.Commit("bob@example.com", testrepo.DaysAgo(2), nil)
+	b.Commit("bob@example.com", testrepo.DaysAgo(1), nil)
+	b.Commit("bob@example.com", testrepo.DaysAgo(1), nil)
+
+	out := mustRun(t, "--per-repo", "--count=1", a.Dir, b.Dir)
+	sections := map[string]string{}
+	for _, section := range strings.Split(out, "\n--- Top Likely Owners of ")[1:] {
+		repo, body, _ := strings.Cut(section, " ---\n")
+		body, _, _ = strings.Cut(body, "\n\n") // The parameters follow the last section
+		sections[repo] = strings.TrimSuffix(body, "\n")
+	}
+	for repo, want := range map[string]string{a.Dir: "1. alice@example.com ", b.Dir: "1. bob@example.com "} {
+		body, ok := sections[repo]
+		if !ok {
+			t.Errorf("no section for %s in:\n%s", repo, out)
+			continue
+		}
+		if !strings.HasPrefix(body, want) || strings.Contains(body, "\n") {
+			t.Errorf("section of %s: got %q, want only %q", repo, body, want)
+		}
+	}
+}
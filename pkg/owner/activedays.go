@@ -0,0 +1,32 @@
+package owner
+
+import "time"
+
+// Values accepted by --weight-by.
+const (
+	WeightByCommits    = "commits"
+	WeightByActiveDays = "active-days"
+	WeightByRegions    = "regions" // Blame-based, see regionWeight
+)
+
+// activeDayLayout keys active days by the calendar date in the committer's
+// own time zone, so a late-evening commit counts for the day it was made.
+const activeDayLayout = "2006-01-02"
+
+// claimActiveDay credits a commit towards its author's calendar day and
+// returns the part of weight not already credited for that day. Each day is
+// worth the weight of its best commit, so extra commits on the same day add
+// nothing. prior holds the days credited by repositories scanned earlier,
+// which keeps a day spent in several repositories from counting twice.
+func (d *Data) claimActiveDay(prior *Data, canonicalEmail string, when time.Time, weight float64) float64 {
+	day := when.Format(activeDayLayout)
+	best := max(d.activeDays[canonicalEmail][day], prior.activeDays[canonicalEmail][day])
+	if weight <= best {
+		return 0
+	}
+	if _, ok := d.activeDays[canonicalEmail]; !ok {
+		d.activeDays[canonicalEmail] = make(map[string]float64)
+	}
+	d.activeDays[canonicalEmail][day] = weight
+	return weight - best
+}
@@ -0,0 +1,78 @@
+// Package owner scores who owns a set of git repositories. Every commit
+// credits its author with a weight that decays exponentially with age, and
+// owners active in several repositories get a per-repository bonus.
+//
+// Analyze covers the common case, and AnalyzeFunc also reports every scored
+// commit as it goes. The gitowner command builds on the lower
+// level ScanRepos and RankOwners, whose ScanOptions expose every scoring knob.
+package owner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Options configures Analyze.
+type Options struct {
+	Tau          float64           // Decay time constant in days: a commit Tau days old weighs 1/e
+	BonusPerRepo float64           // Multiplicative bonus per additional repository (0.1 = +10%)
+	Count        int               // Maximum number of owners returned; 0 returns all of them
+	AliasMap     map[string]string // alias email -> canonical email, as returned by LoadAliases
+	Now          time.Time         // Reference time commit ages are measured from; zero means time.Now()
+}
+
+// Analyze scores the commit history reachable from HEAD in every repository
+// and returns the owners sorted by descending score. Repositories that cannot
+// be read are skipped with a warning on stderr; an error is returned only if
+// none of them could be analyzed. Cancelling ctx stops the scan early; the
+// owners scored so far are still returned.
+func Analyze(ctx context.Context, repos []string, opts Options) ([]OwnerScore, error) {
+	return AnalyzeFunc(ctx, repos, opts, nil)
+}
+
+// AnalyzeFunc is Analyze, calling onCommit (if not nil) for every scored
+// commit with its repository and the weight credited for it, in walk order.
+// If onCommit returns an error, the scan stops and AnalyzeFunc returns that
+// error.
+func AnalyzeFunc(ctx context.Context, repos []string, opts Options, onCommit CommitFunc) ([]OwnerScore, error) {
+	if opts.Tau <= 0 {
+		return nil, fmt.Errorf("tau must be positive, got %v", opts.Tau)
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	var callbackErr error
+	var record CommitFunc
+	if onCommit != nil {
+		record = func(repo string, c *object.Commit, weight float64) error {
+			callbackErr = onCommit(repo, c, weight)
+			return callbackErr
+		}
+	}
+	data, failed := ScanRepos(ctx, repos, ScanOptions{
+		Tau:            opts.Tau,
+		Now:            now,
+		AliasMap:       opts.AliasMap,
+		SampleRate:     1,
+		Identity:       IdentityAuthor,
+		CoauthorWeight: 1,
+		WeightBy:       WeightByCommits,
+		InvalidEmails:  InvalidEmailsKeep,
+		OnCommit:       record,
+	})
+	if callbackErr != nil {
+		return nil, callbackErr
+	}
+	if len(repos) > 0 && len(failed) == len(repos) {
+		return nil, fmt.Errorf("all %d repositories failed", len(repos))
+	}
+	owners, _ := RankOwners(data, RankOptions{BonusPerRepo: opts.BonusPerRepo, Now: now})
+	if opts.Count > 0 {
+		owners = TopN(owners, opts.Count)
+	}
+	return owners, nil
+}
@@ -0,0 +1,88 @@
+package owner_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mateobur/gitowner/internal/testrepo"
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+func TestAnalyze(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(400), nil)
+	r.Commit("bob@example.com", testrepo.DaysAgo(10), nil)
+	r.Commit("alice@example.com", testrepo.DaysAgo(5), nil)
+
+	owners, err := owner.Analyze(context.Background(), []string{r.Dir}, owner.Options{Tau: 365, BonusPerRepo: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(owners) != 2 {
+		t.Fatalf("got %d owners, want 2", len(owners))
+	}
+	if owners[0].Email != "alice@example.com" || owners[1].Email != "bob@example.com" {
+		t.Errorf("got ranking %s, %s; want alice, bob", owners[0].Email, owners[1].Email)
+	}
+	if owners[0].CommitCount != 2 || owners[0].RepoCount != 1 {
+		t.Errorf("alice: got %d commits in %d repos, want 2 in 1", owners[0].CommitCount, owners[0].RepoCount)
+	}
+}
+
+func TestAnalyzeFailsWithoutReadableRepos(t *testing.T) {
+	if _, err := owner.Analyze(context.Background(), []string{t.TempDir()}, owner.Options{Tau: 365}); err == nil {
+		t.Error("expected an error for a directory that is not a repository")
+	}
+}
+
+func TestAnalyzeFuncReportsEveryCommit(t *testing.T) {
+	a, b := testrepo.New(t), testrepo.New(t)
+	a.Commit("alice@example.com", testrepo.DaysAgo(400), nil)
+	a.Commit("bob@example.com", testrepo.DaysAgo(10), nil)
+	b.Commit("alice@example.com", testrepo.DaysAgo(5), nil)
+	b.Commit("carol@example.com", testrepo.DaysAgo(50), nil)
+
+	totals := make(map[string]float64)
+	calls := make(map[string]int)
+	onCommit := func(repo string, c *object.Commit, weight float64) error {
+		totals[c.Author.Email] += weight
+		calls[repo]++
+		return nil
+	}
+	owners, err := owner.AnalyzeFunc(context.Background(), []string{a.Dir, b.Dir}, owner.Options{Tau: 365}, onCommit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls[a.Dir] != 2 || calls[b.Dir] != 2 {
+		t.Errorf("got calls per repository %v, want 2 each", calls)
+	}
+	if len(owners) != len(totals) {
+		t.Fatalf("got %d owners and %d emails from the callback", len(owners), len(totals))
+	}
+	for _, o := range owners {
+		if diff := math.Abs(totals[o.Email] - o.RawScore); diff > 1e-9 {
+			t.Errorf("%s: callback weights add up to %g, want %g", o.Email, totals[o.Email], o.RawScore)
+		}
+	}
+}
+
+func TestAnalyzeFuncStopsOnError(t *testing.T) {
+	r := testrepo.New(t)
+	for i := 0; i < 5; i++ {
+		r.Commit("alice@example.com", testrepo.DaysAgo(1), nil)
+	}
+	stop := errors.New("stop")
+	calls := 0
+	_, err := owner.AnalyzeFunc(context.Background(), []string{r.Dir, r.Dir}, owner.Options{Tau: 365}, func(string, *object.Commit, float64) error {
+		if calls++; calls == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || calls != 2 {
+		t.Errorf("got error %v after %d calls, want the callback's error after 2", err, calls)
+	}
+}
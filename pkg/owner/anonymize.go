@@ -0,0 +1,90 @@
+package owner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// anonymousTokenLen is the number of hex digits in an anonymous token. Longer
+// tokens are used only if two emails of a run would otherwise share one.
+const anonymousTokenLen = 8
+
+// AnonymousTokens maps every email to a stable token: the leading hex digits
+// of SHA-256 over salt followed by the email. All tokens of one call have the
+// same length, the shortest (at least anonymousTokenLen) that keeps them
+// distinct.
+func AnonymousTokens(emails map[string]struct{}, salt string) map[string]string {
+	digests := make(map[string]string, len(emails))
+	for email := range emails {
+		sum := sha256.Sum256([]byte(salt + email))
+		digests[email] = hex.EncodeToString(sum[:])
+	}
+	for n := anonymousTokenLen; ; n++ {
+		tokens := make(map[string]string, len(digests))
+		used := make(map[string]struct{}, len(digests))
+		for email, digest := range digests {
+			tokens[email] = digest[:n]
+			used[digest[:n]] = struct{}{}
+		}
+		if len(used) == len(tokens) || n == sha256.Size*2 {
+			return tokens
+		}
+	}
+}
+
+// Anonymize replaces every email in data by its token from AnonymousTokens
+// and drops author names and alias emails, which would identify the same
+// people. Scores, repositories, commits and files are kept, so rankings are
+// unchanged. It returns the tokens by canonical email.
+func Anonymize(data *Data, salt string) map[string]string {
+	emails := make(map[string]struct{})
+	for email := range data.Scores {
+		emails[email] = struct{}{}
+	}
+	for email := range data.repos {
+		emails[email] = struct{}{}
+	}
+	for email := range data.Invalid {
+		emails[email] = struct{}{}
+	}
+	for _, weights := range data.Files {
+		for email := range weights {
+			emails[email] = struct{}{}
+		}
+	}
+	for _, c := range data.CommitLog {
+		emails[c.Email] = struct{}{}
+	}
+	tokens := AnonymousTokens(emails, salt)
+
+	data.Scores = renameKeys(data.Scores, tokens)
+	data.repos = renameKeys(data.repos, tokens)
+	data.commits = renameKeys(data.commits, tokens)
+	data.days = renameKeys(data.days, tokens)
+	data.vars = renameKeys(data.vars, tokens)
+	data.tickets = renameKeys(data.tickets, tokens)
+	data.Invalid = renameKeys(data.Invalid, tokens)
+	data.RepoScores = renameKeys(data.RepoScores, tokens)
+	data.LastActive = renameKeys(data.LastActive, tokens)
+	data.AgeCounts = renameKeys(data.AgeCounts, tokens)
+	data.activeDays = renameKeys(data.activeDays, tokens)
+	for key, weights := range data.Files {
+		data.Files[key] = renameKeys(weights, tokens)
+	}
+	for i := range data.CommitLog {
+		data.CommitLog[i].Email = tokens[data.CommitLog[i].Email]
+		data.CommitLog[i].Name = ""
+	}
+	data.names = make(map[string]map[string]int)
+	data.aliases = make(map[string]map[string]struct{})
+	return tokens
+}
+
+// renameKeys returns m with every key replaced by its entry in names.
+func renameKeys[V any](m map[string]V, names map[string]string) map[string]V {
+	renamed := make(map[string]V, len(m))
+	for key, v := range m {
+		renamed[names[key]] = v
+	}
+	return renamed
+}
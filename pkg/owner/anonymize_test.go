@@ -0,0 +1,59 @@
+package owner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestAnonymousTokens(t *testing.T) {
+	emails := make(map[string]struct{})
+	for i := 0; i < 1000; i++ {
+		emails[fmt.Sprintf("dev%d@example.com", i)] = struct{}{}
+	}
+	tokens := AnonymousTokens(emails, "salt")
+	again := AnonymousTokens(emails, "salt")
+	seen := make(map[string]string, len(tokens))
+	for email, token := range tokens {
+		if again[email] != token {
+			t.Errorf("%s: got %s, then %s", email, token, again[email])
+		}
+		if other, dup := seen[token]; dup {
+			t.Errorf("%s and %s share token %s", email, other, token)
+		}
+		seen[token] = email
+	}
+	sum := sha256.Sum256([]byte("salt" + "dev1@example.com"))
+	if got, want := tokens["dev1@example.com"], hex.EncodeToString(sum[:])[:anonymousTokenLen]; got != want {
+		t.Errorf("got token %s, want %s", got, want)
+	}
+	if unsalted := AnonymousTokens(emails, ""); unsalted["dev1@example.com"] == tokens["dev1@example.com"] {
+		t.Error("the salt did not change the token")
+	}
+}
+
+func TestAnonymizeKeepsRanking(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(5), nil)
+	r.Commit("Alice@Example.com", testrepo.DaysAgo(4), nil)
+	r.Commit("bob@example.com", testrepo.DaysAgo(1), nil)
+	data, plain := scan(t, testOptions(), r.Dir)
+	tokens := Anonymize(data, "salt")
+	anonymous, _ := RankOwners(data, RankOptions{Now: testrepo.Now})
+
+	if len(anonymous) != len(plain) {
+		t.Fatalf("got %d owners, want %d", len(anonymous), len(plain))
+	}
+	for i, o := range anonymous {
+		p := plain[i]
+		if o.Email != tokens[p.Email] || o.Score != p.Score || o.RepoCount != p.RepoCount || o.CommitCount != p.CommitCount {
+			t.Errorf("rank %d: got %+v, want %+v under token %s", i+1, o, p, tokens[p.Email])
+		}
+		if o.Name != "" || len(o.AliasesUsed) != 0 {
+			t.Errorf("rank %d: name %q or aliases %v left in", i+1, o.Name, o.AliasesUsed)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package owner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// blamedCommit aggregates the surviving lines a single commit contributed.
+type blamedCommit struct {
+	sig   object.Signature
+	lines float64 // Surviving lines, each weighted by its file's blast-radius factor
+}
+
+// processRepoBlame scores a repository by blaming every file in its HEAD tree.
+// Each surviving line is credited to its last author with weight
+// exp(-age/tau), where age is the time since the line was last modified.
+// With WeightBy regions each line instead counts regionWeight of the
+// contiguous region by the same author it belongs to.
+// PathPrefixes, IgnorePaths, Since, Until, ExcludeRanges and the excluded
+// identities are honored; commit-level options (sampling, identity, reverts,
+// tickets, ...) do not apply to blame.
+//
+// Blame replays the history of every file, so this is far slower than the
+// commit walk. Files are blamed concurrently by BlameWorkers goroutines, each
+// with its own handle on the repository. With BlameCache, per-file results are
+// kept across runs and reused while the file's blob is unchanged. If ctx is
+// cancelled, files not yet blamed are left out and the rest are scored.
+func processRepoBlame(ctx context.Context, repoPath string, opts ScanOptions, data *Data) error {
+	Infof("Blaming repository: %s", repoPath)
+	repo, head, err := OpenRepo(repoPath, opts.Ref)
+	if err != nil {
+		return err
+	}
+	commit, err := repo.CommitObject(head)
+	if err != nil {
+		return fmt.Errorf("failed to load the scored commit of %s: %w: %w", repoPath, ErrNoHead, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to load HEAD tree of %s: %w", repoPath, err)
+	}
+
+	// Only files whose blob changed since the cached run need to be blamed again
+	var cache *blameCache
+	if opts.BlameCache {
+		cache = loadBlameCache(repoPath)
+	}
+	ignore := ignoreMatcher(opts.IgnorePaths)
+	inHead := make(map[string]struct{})
+	results := make(map[string][]blameGroup)
+	blobs := make(map[string]string)
+	var pending []string
+	err = tree.Files().ForEach(func(f *object.File) error {
+		inHead[f.Name] = struct{}{}
+		if len(opts.PathPrefixes) > 0 && len(pathsInScope([]string{f.Name}, opts.PathPrefixes)) == 0 {
+			return nil
+		}
+		if pathIgnored(ignore, f.Name) || !opts.hasExtension(f.Name) {
+			return nil
+		}
+		if binary, err := f.IsBinary(); err != nil || binary {
+			return nil // Binary files have no meaningful lines
+		}
+		blobs[f.Name] = f.Hash.String()
+		if cache != nil {
+			if groups, ok := cache.lookup(f.Name, f.Hash.String()); ok {
+				results[f.Name] = groups
+				return nil
+			}
+		}
+		pending = append(pending, f.Name)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list files of %s: %w", repoPath, err)
+	}
+	if cache != nil && len(results) > 0 {
+		Infof("Reusing cached blame for %d of %d files.", len(results), len(results)+len(pending))
+	}
+
+	blamed, err := blameFiles(ctx, repoPath, head, pending, opts.BlameWorkers, opts.Progress)
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		Warnf("Warning: blame of %s interrupted (%v) after %d of %d files. Keeping partial results.", repoPath, context.Cause(ctx), len(blamed), len(pending))
+	}
+	for path, groups := range blamed {
+		results[path] = groups
+	}
+
+	if cache != nil {
+		for path := range cache.Entries {
+			if _, ok := inHead[path]; !ok {
+				delete(cache.Entries, path) // The file no longer exists
+			}
+		}
+		for path, groups := range blamed {
+			cache.Entries[path] = blameCacheEntry{Blob: blobs[path], Groups: groups}
+		}
+		if err := cache.save(); err != nil {
+			Warnf("Warning: could not write blame cache: %v", err)
+		}
+	}
+
+	// Sum each commit's surviving lines, weighting every line by its file's blast radius
+	commits := make(map[string]*blamedCommit)
+	for path, groups := range results {
+		factor := opts.Dependents.fileFactor(path)
+		for _, g := range groups {
+			bc, ok := commits[g.Hash]
+			if !ok {
+				bc = &blamedCommit{sig: object.Signature{Name: g.Name, Email: g.Email, When: g.When}}
+				commits[g.Hash] = bc
+			}
+			lines := float64(g.Lines)
+			if opts.WeightBy == WeightByRegions {
+				lines = g.RegionLines
+			}
+			bc.lines += lines * factor
+		}
+	}
+
+	now := opts.Now
+	decay := opts.decayer()
+	future := 0
+	for _, bc := range commits {
+		if opts.timeExcluded(bc.sig.When) || opts.signatureExcluded(bc.sig) || bc.sig.Email == "" {
+			continue
+		}
+		if opts.futureDated(bc.sig.When) {
+			future++
+			if opts.DropFutureDated {
+				continue
+			}
+		}
+		daysAgo := math.Max(0, now.Sub(bc.sig.When).Hours()/24)
+		weight := bc.lines * decay(daysAgo) * opts.repoWeight(repoPath)
+		canonicalEmail, ok := data.screenEmail(bc.sig.Email, opts.canonicalEmail(bc.sig.Email), opts.InvalidEmails)
+		if !ok {
+			continue
+		}
+		Debugf("%s blame %s: %.1f lines, %.4f", repoPath, canonicalEmail, bc.lines, weight)
+		data.record(repoPath, bc.sig, canonicalEmail, weight, 0)
+		data.Scored++
+	}
+
+	opts.warnFutureDated(repoPath, future)
+	Infof("Finished blaming %s.", repoPath)
+	return nil
+}
+
+// blameFiles blames paths at the given commit using a pool of workers and
+// returns, for each file, its line counts grouped by the commit that last
+// changed them. With progress set, a progress line is redrawn on LogOutput.
+// No new files are started once ctx is cancelled.
+func blameFiles(ctx context.Context, repoPath string, head plumbing.Hash, paths []string, workers int, progress bool) (map[string][]blameGroup, error) {
+	jobs := make(chan string)
+	var (
+		mu       sync.Mutex
+		results  = make(map[string][]blameGroup)
+		done     int
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// go-git repositories are not safe for concurrent use, so each worker opens its own
+			repo, err := openRepository(repoPath)
+			var commit *object.Commit
+			if err == nil {
+				commit, err = repo.CommitObject(head)
+			}
+			for path := range jobs {
+				var result *git.BlameResult
+				if err == nil {
+					result, err = git.Blame(commit, path)
+					if err != nil {
+						err = fmt.Errorf("failed to blame %s in %s: %w", path, repoPath, err)
+					}
+				}
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				if result != nil {
+					results[path] = groupBlame(result)
+				}
+				done++
+				if progress {
+					logf(LevelInfo, "\rBlaming files: %d/%d", done, len(paths))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for _, path := range paths {
+		select {
+		case jobs <- path:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if progress && len(paths) > 0 {
+		logf(LevelInfo, "\n")
+	}
+	return results, firstErr
+}
+
+// regionWeight is the weight of each line in a contiguous region of size
+// lines last written by one author: 1 + ln(size). A single edited line counts
+// 1, while every line of a 20-line function written in one piece counts ~4,
+// so coherent authorship outweighs the same number of scattered edits.
+func regionWeight(size int) float64 {
+	return 1 + math.Log(float64(size))
+}
+
+// groupBlame counts the lines of a blame result per commit, in order of first
+// appearance. Alongside the plain count it sums each line's regionWeight,
+// where a region is a run of consecutive lines by the same author (possibly
+// across several of their commits).
+func groupBlame(result *git.BlameResult) []blameGroup {
+	var groups []blameGroup
+	index := make(map[plumbing.Hash]int)
+	for _, line := range result.Lines {
+		i, ok := index[line.Hash]
+		if !ok {
+			i = len(groups)
+			index[line.Hash] = i
+			groups = append(groups, blameGroup{Hash: line.Hash.String(), Name: line.AuthorName, Email: line.Author, When: line.Date})
+		}
+		groups[i].Lines++
+	}
+
+	lines := result.Lines
+	for start := 0; start < len(lines); {
+		end := start + 1
+		for end < len(lines) && lines[end].Author == lines[start].Author {
+			end++
+		}
+		w := regionWeight(end - start)
+		for _, line := range lines[start:end] {
+			groups[index[line.Hash]].RegionLines += w
+		}
+		start = end
+	}
+	return groups
+}
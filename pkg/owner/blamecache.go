@@ -0,0 +1,119 @@
+package owner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// blameCacheVersion is bumped whenever the cache layout changes; caches with
+// another version are ignored.
+const blameCacheVersion = 2
+
+// blameGroup is the number of lines of one file last changed by one commit.
+type blameGroup struct {
+	Hash        string    `json:"hash"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	When        time.Time `json:"when"`
+	Lines       int       `json:"lines"`
+	RegionLines float64   `json:"region_lines"` // Lines weighted by regionWeight of the region around them
+}
+
+// blameCacheEntry is the cached blame of one file at one blob.
+type blameCacheEntry struct {
+	Blob   string       `json:"blob"`
+	Groups []blameGroup `json:"groups"`
+}
+
+// blameCache stores per-file blame results of one repository across runs,
+// keyed by path. An entry is only reused while the file's blob hash is
+// unchanged, so only edited files are blamed again.
+type blameCache struct {
+	file    string
+	Version int                        `json:"version"`
+	Entries map[string]blameCacheEntry `json:"entries"`
+}
+
+// blameCacheFile returns the cache file for a repository under the user's
+// cache directory ($XDG_CACHE_HOME/gitowner/blame on Linux).
+func blameCacheFile(repoPath string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, "gitowner", "blame", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// loadBlameCache reads the blame cache of a repository. A missing, unreadable
+// or outdated cache yields an empty one, so the cache never blocks a run.
+func loadBlameCache(repoPath string) *blameCache {
+	cache := &blameCache{Version: blameCacheVersion, Entries: make(map[string]blameCacheEntry)}
+	file, err := blameCacheFile(repoPath)
+	if err != nil {
+		return cache
+	}
+	cache.file = file
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return cache
+	}
+	var stored blameCache
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		Warnf("Warning: ignoring unreadable blame cache %s", file)
+		return cache
+	}
+	if stored.Version != blameCacheVersion {
+		return cache // Written by another version; rebuilt on save
+	}
+	if stored.Entries != nil {
+		cache.Entries = stored.Entries
+	}
+	return cache
+}
+
+// lookup returns the cached blame of path if it was computed for blob.
+func (c *blameCache) lookup(path, blob string) ([]blameGroup, bool) {
+	entry, ok := c.Entries[path]
+	if !ok || entry.Blob != blob {
+		return nil, false
+	}
+	return entry.Groups, true
+}
+
+// save writes the cache atomically (write to a temporary file, then rename).
+func (c *blameCache) save() error {
+	if c.file == "" {
+		return fmt.Errorf("no user cache directory available")
+	}
+	if err := os.MkdirAll(filepath.Dir(c.file), 0o755); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.file), ".blame-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.file)
+}
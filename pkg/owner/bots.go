@@ -0,0 +1,20 @@
+package owner
+
+import (
+	"regexp"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// botEmails and botNames recognize automated committers for ExcludeBots. GitHub's
+// per-user noreply addresses (123+user@users.noreply.github.com) belong to
+// people and are not matched; only a noreply local part is.
+var (
+	botEmails = regexp.MustCompile(`(?i)\[bot\]|^(no-?reply|bot|ci|build|builds|jenkins|buildbot|actions|automation)@`)
+	botNames  = regexp.MustCompile(`(?i)\[bot\]|^(dependabot|renovate|github-actions|greenkeeper|snyk-bot|semantic-release-bot|pre-commit-ci|mergify|jenkins|gitlab ci)\b`)
+)
+
+// isBot reports whether a signature belongs to a well-known bot or CI account.
+func isBot(sig object.Signature) bool {
+	return botEmails.MatchString(sig.Email) || botNames.MatchString(sig.Name)
+}
@@ -0,0 +1,44 @@
+package owner
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestIsBot(t *testing.T) {
+	tests := []struct {
+		name, email string
+		want        bool
+	}{
+		{"dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com", true},
+		{"github-actions", "41898282+github-actions[bot]@users.noreply.github.com", true},
+		{"Renovate Bot", "bot@renovateapp.com", true},
+		{"Jenkins", "jenkins@ci.example.com", true},
+		{"Release", "NoReply@example.com", true},
+		{"GITLAB CI", "gitlab@example.com", true},
+		{"Jane Doe", "12345+jane@users.noreply.github.com", false},
+		{"Robert", "robot.enthusiast@example.com", false},
+		{"Ci Ling", "ciling@example.com", false},
+	}
+	for _, tt := range tests {
+		if got := isBot(object.Signature{Name: tt.name, Email: tt.email}); got != tt.want {
+			t.Errorf("isBot(%q <%s>) = %v, want %v", tt.name, tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestExcludeBots(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("dev@example.com", testrepo.DaysAgo(3), nil)
+	for i := 0; i < 5; i++ {
+		r.Commit("49699333+dependabot[bot]@users.noreply.github.com", testrepo.DaysAgo(1), nil)
+	}
+	opts := testOptions()
+	opts.ExcludeBots = true
+	_, owners := scan(t, opts, r.Dir)
+	if len(owners) != 1 || owners[0].Email != "dev@example.com" {
+		t.Errorf("got %+v, want only dev@example.com", owners)
+	}
+}
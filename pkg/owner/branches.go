@@ -0,0 +1,115 @@
+package owner
+
+import (
+	"errors"
+	"io"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// branchTips returns the commits at the tips of every local and
+// remote-tracking branch, plus HEAD (which may be detached) and, with tags,
+// every tagged commit. Symbolic refs such as origin/HEAD are skipped, since
+// they point at another branch.
+func branchTips(repo *git.Repository, head plumbing.Hash, tags bool) ([]plumbing.Hash, error) {
+	tips := []plumbing.Hash{head}
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference && (ref.Name().IsBranch() || ref.Name().IsRemote()) {
+			tips = append(tips, ref.Hash())
+		}
+		return nil
+	})
+	if err != nil || !tags {
+		return tips, err
+	}
+	tagged, err := releaseTips(repo)
+	if err != nil {
+		return nil, err
+	}
+	return append(tips, tagged...), nil
+}
+
+// walkFrom returns the history reachable from tips: the plain log for a single
+// tip, or a unified walk over several.
+func walkFrom(repo *git.Repository, tips []plumbing.Hash) (object.CommitIter, error) {
+	if len(tips) == 1 {
+		return repo.Log(&git.LogOptions{From: tips[0]})
+	}
+	return newUnifiedWalk(repo, tips), nil
+}
+
+// unifiedWalk iterates over the union of the histories reachable from several
+// tips, visiting every commit exactly once. Walking each branch separately
+// would revisit the shared history once per branch.
+type unifiedWalk struct {
+	repo  *git.Repository
+	stack []plumbing.Hash
+	seen  map[plumbing.Hash]struct{}
+}
+
+// newUnifiedWalk starts a walk from the given tips.
+func newUnifiedWalk(repo *git.Repository, tips []plumbing.Hash) *unifiedWalk {
+	w := &unifiedWalk{repo: repo, seen: make(map[plumbing.Hash]struct{})}
+	// Pushed in reverse so the first tip is walked first
+	for i := len(tips) - 1; i >= 0; i-- {
+		w.push(tips[i])
+	}
+	return w
+}
+
+// push schedules a commit unless it was already scheduled.
+func (w *unifiedWalk) push(hash plumbing.Hash) {
+	if _, ok := w.seen[hash]; ok {
+		return
+	}
+	w.seen[hash] = struct{}{}
+	w.stack = append(w.stack, hash)
+}
+
+// Next returns the next unvisited commit, or io.EOF when the walk is done.
+func (w *unifiedWalk) Next() (*object.Commit, error) {
+	if len(w.stack) == 0 {
+		return nil, io.EOF
+	}
+	hash := w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+	c, err := w.repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(c.ParentHashes) - 1; i >= 0; i-- {
+		w.push(c.ParentHashes[i])
+	}
+	return c, nil
+}
+
+// ForEach calls cb for every commit of the walk; returning storer.ErrStop ends it early.
+func (w *unifiedWalk) ForEach(cb func(*object.Commit) error) error {
+	for {
+		c, err := w.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := cb(c); err != nil {
+			if errors.Is(err, storer.ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// Close releases the walk's pending state.
+func (w *unifiedWalk) Close() {
+	w.stack = nil
+}
@@ -0,0 +1,182 @@
+package owner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+// branchyRepo builds a repository with a shared history of base commits and
+// branches that each add their own commits on top of it.
+func branchyRepo(tb testing.TB, base, branches, perBranch int) *testrepo.Repo {
+	tb.Helper()
+	r := testrepo.New(tb)
+	for i := 0; i < base; i++ {
+		r.Commit("base@example.com", testrepo.DaysAgo(float64(1000-i)), nil)
+	}
+	for b := 0; b < branches; b++ {
+		r.Checkout("master")
+		r.Branch(fmt.Sprintf("feature-%d", b))
+		for i := 0; i < perBranch; i++ {
+			r.Commit(fmt.Sprintf("dev%d@example.com", b), testrepo.DaysAgo(float64(100-i)), nil)
+		}
+	}
+	r.Checkout("master")
+	return r
+}
+
+// naiveBranchWalk walks every tip's history separately, as --all-branches
+// did before the unified walk, and returns the number of commits visited.
+func naiveBranchWalk(repo *git.Repository, tips []plumbing.Hash) (int, error) {
+	visited := 0
+	for _, tip := range tips {
+		iter, err := repo.Log(&git.LogOptions{From: tip})
+		if err != nil {
+			return 0, err
+		}
+		err = iter.ForEach(func(*object.Commit) error {
+			visited++
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return visited, nil
+}
+
+func TestUnifiedWalkVisitsEachCommitOnce(t *testing.T) {
+	r := branchyRepo(t, 5, 3, 2)
+	repo, head, err := OpenRepo(r.Dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tips, err := branchTips(repo, head, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[plumbing.Hash]int)
+	err = newUnifiedWalk(repo, tips).ForEach(func(c *object.Commit) error {
+		seen[c.Hash]++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 5+3*2 {
+		t.Errorf("visited %d distinct commits, want %d", len(seen), 5+3*2)
+	}
+	for hash, n := range seen {
+		if n != 1 {
+			t.Errorf("commit %s visited %d times", hash, n)
+		}
+	}
+}
+
+func BenchmarkBranchWalk(b *testing.B) {
+	r := branchyRepo(b, 200, 8, 5)
+	repo, head, err := OpenRepo(r.Dir, "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	tips, err := branchTips(repo, head, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := naiveBranchWalk(repo, tips); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("unified", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			err := newUnifiedWalk(repo, tips).ForEach(func(*object.Commit) error { return nil })
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestRefSelectsBranch(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("main@example.com", testrepo.DaysAgo(10), nil)
+	r.Branch("release")
+	r.Commit("release@example.com", testrepo.DaysAgo(5), nil)
+	r.Checkout("master")
+	r.Commit("trunk@example.com", testrepo.DaysAgo(1), nil)
+
+	for ref, want := range map[string][]string{
+		"":        {"main@example.com", "trunk@example.com"},
+		"master":  {"main@example.com", "trunk@example.com"},
+		"release": {"main@example.com", "release@example.com"},
+	} {
+		opts := testOptions()
+		opts.Ref = ref
+		_, owners := scan(t, opts, r.Dir)
+		got := scores(owners)
+		if len(got) != len(want) {
+			t.Errorf("--ref %q: got %v, want %v", ref, got, want)
+		}
+		for _, email := range want {
+			if _, ok := got[email]; !ok {
+				t.Errorf("--ref %q: %s missing from %v", ref, email, got)
+			}
+		}
+	}
+
+	opts := testOptions()
+	opts.Ref = "no-such-branch"
+	if _, failed := ScanRepos(context.Background(), []string{r.Dir}, opts); len(failed) != 1 {
+		t.Error("expected an unknown ref to fail the repository")
+	}
+}
+
+func TestAllBranches(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("main@example.com", testrepo.DaysAgo(10), nil)
+	r.Branch("side")
+	r.Commit("side@example.com", testrepo.DaysAgo(5), nil)
+	r.Checkout("master")
+	r.Branch("gone")
+	tagged := r.Commit("tagged@example.com", testrepo.DaysAgo(4), nil)
+	r.Tag("v1", tagged)
+	r.Checkout("master")
+	if err := r.Repo.Storer.RemoveReference(plumbing.NewBranchReferenceName("gone")); err != nil {
+		t.Fatal(err)
+	}
+	r.Commit("trunk@example.com", testrepo.DaysAgo(1), nil)
+	age := map[string]float64{"main@example.com": 10, "side@example.com": 5, "tagged@example.com": 4, "trunk@example.com": 1}
+
+	tests := []struct {
+		allBranches, allRefs bool
+		want                 []string
+	}{
+		{false, false, []string{"main@example.com", "trunk@example.com"}},
+		{true, false, []string{"main@example.com", "side@example.com", "trunk@example.com"}},
+		{true, true, []string{"main@example.com", "side@example.com", "tagged@example.com", "trunk@example.com"}},
+	}
+	for _, tt := range tests {
+		opts := testOptions()
+		opts.AllBranches, opts.AllRefs = tt.allBranches, tt.allRefs
+		_, owners := scan(t, opts, r.Dir)
+		got := scores(owners)
+		if len(got) != len(tt.want) {
+			t.Errorf("branches %v, refs %v: got %v, want %v", tt.allBranches, tt.allRefs, got, tt.want)
+		}
+		for _, email := range tt.want {
+			// Each commit is counted once, however many branches reach it
+			if !near(got[email], math.Exp(-age[email]/365)) {
+				t.Errorf("branches %v, refs %v: %s scored %g", tt.allBranches, tt.allRefs, email, got[email])
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package owner
+
+import (
+	"fmt"
+	"math"
+)
+
+// Values accepted by --decay, each giving Tau its own meaning.
+const (
+	DecayExponential = "exponential" // exp(-daysAgo/Tau): a commit Tau days old weighs 1/e
+	DecayLinear      = "linear"      // max(0, 1 - daysAgo/Tau): weight reaches 0 at Tau days
+	DecayStep        = "step"        // 1 within Tau days, 0 after
+	DecayNone        = "none"        // Always 1: a plain commit count; Tau is ignored
+)
+
+// decayFunc maps a commit's age in days to its recency factor.
+type decayFunc func(daysAgo, tau float64) float64
+
+var decayFuncs = map[string]decayFunc{
+	DecayExponential: func(daysAgo, tau float64) float64 { return math.Exp(-daysAgo / tau) },
+	DecayLinear:      func(daysAgo, tau float64) float64 { return math.Max(0, 1-daysAgo/tau) },
+	DecayStep: func(daysAgo, tau float64) float64 {
+		if daysAgo <= tau {
+			return 1
+		}
+		return 0
+	},
+	DecayNone: func(daysAgo, tau float64) float64 { return 1 },
+}
+
+// ValidDecay reports an error unless kind names a decay function ("" means exponential).
+func ValidDecay(kind string) error {
+	if _, ok := decayFuncs[kind]; !ok && kind != "" {
+		return fmt.Errorf("%w: unknown decay %q (expected exponential, linear, step, or none)", ErrParse, kind)
+	}
+	return nil
+}
+
+// decayer selects the Decay function once and returns the recency factor of
+// an age in days, never less than WeightFloor.
+func (o ScanOptions) decayer() func(daysAgo float64) float64 {
+	f, ok := decayFuncs[o.Decay]
+	if !ok {
+		f = decayFuncs[DecayExponential]
+	}
+	return func(daysAgo float64) float64 {
+		return math.Max(f(daysAgo, o.Tau), o.WeightFloor)
+	}
+}
@@ -0,0 +1,53 @@
+package owner
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDecayModes(t *testing.T) {
+	const tau = 100.0
+	tests := []struct {
+		decay string
+		want  map[float64]float64 // days ago -> weight
+	}{
+		{DecayExponential, map[float64]float64{0: 1, 50: math.Exp(-0.5), 100: 1 / math.E, 300: math.Exp(-3)}},
+		{DecayLinear, map[float64]float64{0: 1, 50: 0.5, 100: 0, 300: 0}},
+		{DecayStep, map[float64]float64{0: 1, 50: 1, 100: 1, 300: 0}},
+		{DecayNone, map[float64]float64{0: 1, 50: 1, 100: 1, 300: 1}},
+		{"", map[float64]float64{50: math.Exp(-0.5)}}, // Exponential by default
+	}
+	for _, tt := range tests {
+		decay := ScanOptions{Decay: tt.decay, Tau: tau}.decayer()
+		for days, want := range tt.want {
+			if got := decay(days); !near(got, want) {
+				t.Errorf("%q at %g days: got %g, want %g", tt.decay, days, got, want)
+			}
+		}
+	}
+
+	// At the same age the modes order as expected
+	at := func(kind string) float64 { return ScanOptions{Decay: kind, Tau: tau}.decayer()(50) }
+	if !(at(DecayLinear) < at(DecayExponential) && at(DecayExponential) < at(DecayStep) && at(DecayStep) == at(DecayNone)) {
+		t.Error("expected linear < exponential < step = none at half of tau")
+	}
+}
+
+func TestDecayWeightFloor(t *testing.T) {
+	decay := ScanOptions{Decay: DecayLinear, Tau: 100, WeightFloor: 0.1}.decayer()
+	if got := decay(500); got != 0.1 {
+		t.Errorf("got %g past tau, want the 0.1 floor", got)
+	}
+}
+
+func TestValidDecay(t *testing.T) {
+	for _, kind := range []string{"", DecayExponential, DecayLinear, DecayStep, DecayNone} {
+		if err := ValidDecay(kind); err != nil {
+			t.Errorf("ValidDecay(%q): %v", kind, err)
+		}
+	}
+	if err := ValidDecay("quadratic"); !errors.Is(err, ErrParse) {
+		t.Errorf("ValidDecay(quadratic) = %v, want ErrParse", err)
+	}
+}
@@ -0,0 +1,66 @@
+package owner
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// --- Structure for the TOML Dependencies File ---
+type DepsConfig struct {
+	Dependents map[string][]string `toml:"dependents"` // file -> files that import it
+}
+
+// DependencyMap holds the number of files that directly depend on each file,
+// keyed by repository-relative path.
+type DependencyMap map[string]int
+
+// LoadDependencies loads a --deps-file. The map is generated outside this
+// tool (by a build system or import analyzer) and lists, for each file, the
+// files that import it. Duplicate and self references are ignored.
+func LoadDependencies(filePath string) (DependencyMap, error) {
+	var config DepsConfig
+	if _, err := toml.DecodeFile(filePath, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse dependencies file %s: %w: %w", filePath, ErrParse, err)
+	}
+	deps := make(DependencyMap)
+	for file, dependents := range config.Dependents {
+		file = strings.TrimPrefix(strings.TrimSpace(file), "./")
+		unique := make(map[string]struct{})
+		for _, dependent := range dependents {
+			dependent = strings.TrimPrefix(strings.TrimSpace(dependent), "./")
+			if dependent != "" && dependent != file {
+				unique[dependent] = struct{}{}
+			}
+		}
+		if file != "" && len(unique) > 0 {
+			deps[file] = len(unique)
+		}
+	}
+	return deps, nil
+}
+
+// fileFactor returns the blast-radius multiplier of a file: 1 + ln(dependents).
+// Files with at most one dependent, or absent from the map, keep weight 1.
+func (m DependencyMap) fileFactor(path string) float64 {
+	n := m[path]
+	if n <= 1 {
+		return 1
+	}
+	return 1 + math.Log(float64(n))
+}
+
+// commitFactor returns the weight multiplier for a commit that changed paths:
+// the average blast-radius multiplier of those files.
+func (m DependencyMap) commitFactor(paths []string) float64 {
+	if len(m) == 0 || len(paths) == 0 {
+		return 1
+	}
+	total := 0.0
+	for _, path := range paths {
+		total += m.fileFactor(path)
+	}
+	return total / float64(len(paths))
+}
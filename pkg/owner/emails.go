@@ -0,0 +1,42 @@
+package owner
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// Policies for identities whose email does not parse (--bucket-invalid-emails, --exclude-invalid-emails).
+const (
+	InvalidEmailsKeep    = "keep"    // Credit the malformed email as its own owner
+	InvalidEmailsBucket  = "bucket"  // Credit all malformed emails to invalidEmailBucket
+	InvalidEmailsExclude = "exclude" // Drop credits to malformed emails
+)
+
+// invalidEmailBucket is the pseudo owner collecting malformed emails under InvalidEmailsBucket.
+const invalidEmailBucket = "(invalid)"
+
+// validEmail reports whether email is a bare RFC 5322 address such as
+// "dev@example.com". Display names, missing or repeated '@' and embedded
+// spaces are rejected.
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Name == "" && strings.EqualFold(addr.Address, email)
+}
+
+// screenEmail applies the invalid-email policy to one credit. The canonical
+// email is checked, so an alias file can map a broken address to a valid
+// one. Malformed emails are counted under their raw form for the report.
+// It returns the key to credit, or false if the credit is dropped.
+func (d *Data) screenEmail(rawEmail, canonicalEmail, policy string) (string, bool) {
+	if validEmail(canonicalEmail) {
+		return canonicalEmail, true
+	}
+	d.Invalid[strings.TrimSpace(rawEmail)]++
+	switch policy {
+	case InvalidEmailsBucket:
+		return invalidEmailBucket, true
+	case InvalidEmailsExclude:
+		return "", false
+	}
+	return canonicalEmail, true
+}
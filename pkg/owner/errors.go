@@ -0,0 +1,22 @@
+package owner
+
+import (
+	"errors"
+)
+
+// Sentinel errors identifying why a repository or input could not be processed.
+// Returned errors wrap one of these, so callers can test with errors.Is.
+var (
+	// ErrRepoNotFound means the path does not contain a Git repository.
+	ErrRepoNotFound = errors.New("repository not found")
+	// ErrEmptyRepo means the repository has no commits (unborn HEAD).
+	ErrEmptyRepo = errors.New("repository has no commits")
+	// ErrNoHead means HEAD exists but could not be resolved to a commit.
+	ErrNoHead = errors.New("cannot resolve HEAD")
+	// ErrRefNotFound means the requested branch, tag or commit does not exist.
+	ErrRefNotFound = errors.New("revision not found")
+	// ErrShallow means history could not be walked because the clone is shallow.
+	ErrShallow = errors.New("shallow repository")
+	// ErrParse means an input file or flag value could not be parsed.
+	ErrParse = errors.New("parse error")
+)
@@ -0,0 +1,67 @@
+package owner
+
+import (
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// fileHistory holds what the file-age pre-pass learned about a history.
+type fileHistory struct {
+	created map[string]time.Time       // path -> time of the first commit touching it, by the primary identity
+	paths   map[plumbing.Hash][]string // commit -> changed paths, reused by the scoring pass
+}
+
+// loadFileHistory walks the history reachable from tips (the same ones as the
+// scoring walk) once, recording every commit's changed paths and the earliest
+// time each path was touched, by the author or committer time as identity
+// says. A renamed file counts as a new file at its new path.
+func loadFileHistory(repo *git.Repository, tips []plumbing.Hash, identity string) (*fileHistory, error) {
+	iter, err := walkFrom(repo, tips)
+	if err != nil {
+		return nil, err
+	}
+	h := &fileHistory{
+		created: make(map[string]time.Time),
+		paths:   make(map[plumbing.Hash][]string),
+	}
+	err = iter.ForEach(func(c *object.Commit) error {
+		paths, err := changedPaths(c)
+		if err != nil {
+			return err
+		}
+		h.paths[c.Hash] = paths
+		when := c.Author.When
+		if identity == IdentityCommitter {
+			when = c.Committer.When
+		}
+		for _, path := range paths {
+			if created, ok := h.created[path]; !ok || when.Before(created) {
+				h.created[path] = when
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// maintenanceFactor returns the weight multiplier for a commit made at when
+// that touched paths: 1 + bonus × the average age in years of those files at
+// the time of the edit. Edits to files created by the same commit add nothing.
+func (h *fileHistory) maintenanceFactor(paths []string, when time.Time, bonus float64) float64 {
+	if len(paths) == 0 {
+		return 1
+	}
+	totalYears := 0.0
+	for _, path := range paths {
+		if created, ok := h.created[path]; ok && when.After(created) {
+			totalYears += when.Sub(created).Hours() / 24 / 365
+		}
+	}
+	return 1 + bonus*totalYears/float64(len(paths))
+}
@@ -0,0 +1,113 @@
+package owner
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DateRange is a half-open time band [Start, End) whose commits are dropped entirely.
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (r DateRange) contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// timeExcluded reports whether a commit made at t falls outside the
+// [Since, Until) window or inside one of the ExcludeRanges.
+func (o ScanOptions) timeExcluded(t time.Time) bool {
+	if (!o.Since.IsZero() && t.Before(o.Since)) || (!o.Until.IsZero() && !t.Before(o.Until)) {
+		return true
+	}
+	for _, r := range o.ExcludeRanges {
+		if r.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// futureDated reports whether a commit made at t is dated more than
+// MaxFutureSkew after Now, which only a wrong clock explains. Ages are
+// differences of instants, so the time zones of t and Now do not matter.
+func (o ScanOptions) futureDated(t time.Time) bool {
+	return o.MaxFutureSkew > 0 && t.Sub(o.Now) > o.MaxFutureSkew
+}
+
+// warnFutureDated reports the commits of a repository that futureDated caught.
+func (o ScanOptions) warnFutureDated(repoPath string, n int) {
+	if n == 0 {
+		return
+	}
+	if o.DropFutureDated {
+		Warnf("Warning: dropped %d commits in %s dated more than %.0fh after the reference time (wrong clock?).", n, repoPath, o.MaxFutureSkew.Hours())
+	} else {
+		Warnf("Warning: %d commits in %s are dated more than %.0fh after the reference time (wrong clock?); they count as brand new (use --drop-future-commits to drop them).", n, repoPath, o.MaxFutureSkew.Hours())
+	}
+}
+
+// signatureExcluded reports whether an identity is filtered out: its
+// canonical email is in ExcludeEmails, its email or name matches one of
+// ExcludePatterns, or it is a bot and ExcludeBots is set.
+func (o ScanOptions) signatureExcluded(sig object.Signature) bool {
+	if _, ok := o.ExcludeEmails[o.canonicalEmail(sig.Email)]; ok {
+		return true
+	}
+	for _, re := range o.ExcludePatterns {
+		if re.MatchString(sig.Email) || re.MatchString(sig.Name) {
+			return true
+		}
+	}
+	return o.ExcludeBots && isBot(sig)
+}
+
+// messageExcluded reports whether a commit message matches one of
+// ExcludeMessages.
+func (o ScanOptions) messageExcluded(message string) bool {
+	for _, re := range o.ExcludeMessages {
+		if re.MatchString(message) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDateBound parses a date bound with parseWhen. For inputs naming a
+// whole day (YYYY-MM-DD, today, yesterday) used as an end bound, the whole
+// day is included.
+func ParseDateBound(value string, now time.Time, isEnd bool) (time.Time, error) {
+	t, err := parseWhen(value, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if isEnd && namesWholeDay(value) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// ParseDateRange parses a "<start>..<end>" band as given to --exclude-date-range.
+// Relative bounds are resolved against now.
+func ParseDateRange(value string, now time.Time) (DateRange, error) {
+	startStr, endStr, ok := strings.Cut(value, "..")
+	if !ok {
+		return DateRange{}, fmt.Errorf("%w: invalid date range %q (expected <start>..<end>)", ErrParse, value)
+	}
+	start, err := ParseDateBound(startStr, now, false)
+	if err != nil {
+		return DateRange{}, err
+	}
+	end, err := ParseDateBound(endStr, now, true)
+	if err != nil {
+		return DateRange{}, err
+	}
+	if !end.After(start) {
+		return DateRange{}, fmt.Errorf("%w: invalid date range %q: end must be after start", ErrParse, value)
+	}
+	return DateRange{Start: start, End: end}, nil
+}
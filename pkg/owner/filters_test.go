@@ -0,0 +1,115 @@
+package owner
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestSinceUntilBoundaries(t *testing.T) {
+	now := testrepo.Now
+	since, err := ParseDateBound("2024-05-01", now, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	until, err := ParseDateBound("2024-05-10T00:00:00Z", now, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := ScanOptions{Since: since, Until: until}
+	tests := []struct {
+		when     time.Time
+		excluded bool
+	}{
+		{time.Date(2024, 4, 30, 23, 59, 59, 0, time.UTC), true},
+		{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), false}, // Since is inclusive
+		{time.Date(2024, 5, 9, 23, 59, 59, 0, time.UTC), false},
+		{time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), true}, // Until is exclusive
+	}
+	for _, tt := range tests {
+		if got := opts.timeExcluded(tt.when); got != tt.excluded {
+			t.Errorf("timeExcluded(%s) = %v, want %v", tt.when, got, tt.excluded)
+		}
+	}
+
+	// An end bound naming a day includes that whole day
+	until, err = ParseDateBound("2024-05-10", now, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Until = until
+	if opts.timeExcluded(time.Date(2024, 5, 10, 23, 0, 0, 0, time.UTC)) {
+		t.Error("a commit on the --until day was excluded")
+	}
+}
+
+func TestParseRelativeDates(t *testing.T) {
+	now := testrepo.Now
+	tests := map[string]time.Time{
+		"90d":       now.AddDate(0, 0, -90),
+		"2w ago":    now.AddDate(0, 0, -14),
+		"6mo ago":   now.AddDate(0, 0, -180),
+		"1y":        now.AddDate(0, 0, -365),
+		"yesterday": time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC),
+	}
+	for value, want := range tests {
+		got, err := ParseDateBound(value, now, false)
+		if err != nil {
+			t.Errorf("%q: %v", value, err)
+		} else if !got.Equal(want) {
+			t.Errorf("%q: got %s, want %s", value, got, want)
+		}
+	}
+	if _, err := ParseDateBound("last tuesday", now, false); err == nil {
+		t.Error("expected an error for an unsupported date")
+	}
+}
+
+func TestSinceFiltersCommits(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("old@example.com", testrepo.DaysAgo(100), nil)
+	r.Commit("new@example.com", testrepo.DaysAgo(10), nil)
+	opts := testOptions()
+	opts.Since = testrepo.DaysAgo(30)
+	_, owners := scan(t, opts, r.Dir)
+	if len(owners) != 1 || owners[0].Email != "new@example.com" {
+		t.Errorf("got %+v, want only new@example.com", owners)
+	}
+}
+
+func TestCommitTimeZonesAndFutureDates(t *testing.T) {
+	r := testrepo.New(t)
+	// The same instant, 30 days ago, written in two time zones
+	utc := testrepo.DaysAgo(30)
+	r.Commit("utc@example.com", utc, nil)
+	r.Commit("tokyo@example.com", utc.In(time.FixedZone("JST", 9*3600)), nil)
+	r.Commit("skewed@example.com", testrepo.Now.Add(30*time.Minute), nil)
+	r.Commit("future@example.com", testrepo.Now.AddDate(0, 0, 2), nil)
+
+	decayed := math.Exp(-30.0 / 365)
+	tests := []struct {
+		drop bool
+		want map[string]float64
+	}{
+		{false, map[string]float64{"utc@example.com": decayed, "tokyo@example.com": decayed, "skewed@example.com": 1, "future@example.com": 1}},
+		{true, map[string]float64{"utc@example.com": decayed, "tokyo@example.com": decayed, "skewed@example.com": 1}},
+	}
+	for _, tt := range tests {
+		opts := testOptions()
+		opts.MaxFutureSkew = time.Hour
+		opts.DropFutureDated = tt.drop
+		_, owners := scan(t, opts, r.Dir)
+		got := scores(owners)
+		if len(got) != len(tt.want) {
+			t.Errorf("drop %v: got %v, want %v", tt.drop, got, tt.want)
+			continue
+		}
+		for email, want := range tt.want {
+			if !near(got[email], want) {
+				t.Errorf("drop %v: %s scored %g, want %g", tt.drop, email, got[email], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package owner
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Values accepted by --identity.
+const (
+	IdentityAuthor    = "author"
+	IdentityCommitter = "committer"
+	IdentityBoth      = "both"
+)
+
+// coauthorTrailer matches a "Co-authored-by: Name <email>" line of a commit message.
+var coauthorTrailer = regexp.MustCompile(`(?im)^[ \t]*co-authored-by:[ \t]*([^<\n]*?)[ \t]*<([^<>\s]+@[^<>\s]+)>[ \t]*$`)
+
+// coauthors returns the well-formed Co-authored-by trailers of a commit as
+// signatures dated like the author's. Lines without a "<user@host>" email are skipped.
+func coauthors(c *object.Commit) []object.Signature {
+	var sigs []object.Signature
+	for _, m := range coauthorTrailer.FindAllStringSubmatch(c.Message, -1) {
+		sigs = append(sigs, object.Signature{Name: strings.TrimSpace(m[1]), Email: m[2], When: c.Author.When})
+	}
+	return sigs
+}
+
+// credit is one identity receiving (a fraction of) a commit's weight.
+type credit struct {
+	Sig            object.Signature
+	CanonicalEmail string
+	Factor         float64
+}
+
+// commitCredits lists who is credited for a commit under opts.Identity.
+//
+// In "both" mode the author receives the full weight and the committer
+// receives CommitterWeight of it, unless both resolve to the same canonical
+// email, in which case the commit is only counted once for the author.
+// Unless only the committer is credited, every Co-authored-by trailer
+// receives CoauthorWeight of the weight, once per canonical email.
+// Signatures without an email, and excluded identities, are never credited.
+func commitCredits(c *object.Commit, opts ScanOptions) []credit {
+	var credits []credit
+	add := func(sig object.Signature, factor float64) {
+		if sig.Email == "" || factor <= 0 || opts.signatureExcluded(sig) {
+			return
+		}
+		canonical := opts.canonicalEmail(sig.Email)
+		for _, existing := range credits {
+			if existing.CanonicalEmail == canonical {
+				return // Same person in both roles: don't double-count
+			}
+		}
+		credits = append(credits, credit{Sig: sig, CanonicalEmail: canonical, Factor: factor})
+	}
+
+	switch opts.Identity {
+	case IdentityCommitter:
+		add(c.Committer, 1)
+	case IdentityBoth:
+		add(c.Author, 1)
+		add(c.Committer, opts.CommitterWeight)
+	default:
+		add(c.Author, 1)
+	}
+	if opts.Identity != IdentityCommitter && opts.CoauthorWeight > 0 {
+		for _, sig := range coauthors(c) {
+			add(sig, opts.CoauthorWeight)
+		}
+	}
+	return credits
+}
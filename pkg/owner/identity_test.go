@@ -0,0 +1,103 @@
+package owner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+// creditedEmails returns the canonical emails credited for c and their factors.
+func creditedEmails(c *object.Commit, opts ScanOptions) map[string]float64 {
+	got := make(map[string]float64)
+	for _, cr := range commitCredits(c, opts) {
+		got[cr.CanonicalEmail] = cr.Factor
+	}
+	return got
+}
+
+func TestCoauthorTrailers(t *testing.T) {
+	author := *testrepo.Sig("author@example.com", testrepo.DaysAgo(1))
+	tests := []struct {
+		name    string
+		message string
+		want    map[string]float64
+	}{
+		{
+			name: "several trailers",
+			message: "Pair on parser\n\nCo-authored-by: Ann <ann@example.com>\n" +
+				"co-authored-by: Ben Bo <Ben@Example.com>\n",
+			want: map[string]float64{"author@example.com": 1, "ann@example.com": 0.5, "ben@example.com": 0.5},
+		},
+		{
+			name: "malformed trailers",
+			message: "Fix\n\nCo-authored-by: No Email\nCo-authored-by: Bad <not-an-email>\n" +
+				"Co-authored-by: Two <a@example.com> <b@example.com>\nCo-authored-by: Ok <ok@example.com>\n",
+			want: map[string]float64{"author@example.com": 1, "ok@example.com": 0.5},
+		},
+		{
+			name: "duplicate co-authors",
+			message: "Fix\n\nCo-authored-by: Ann <ann@example.com>\nCo-authored-by: Ann A. <ANN@example.com>\n" +
+				"Co-authored-by: Me <author@example.com>\n",
+			want: map[string]float64{"author@example.com": 1, "ann@example.com": 0.5},
+		},
+		{
+			name:    "trailer in the middle of a line",
+			message: "Mention Co-authored-by: Ann <ann@example.com> inline\n",
+			want:    map[string]float64{"author@example.com": 1},
+		},
+	}
+	opts := testOptions()
+	opts.CoauthorWeight = 0.5
+	for _, tt := range tests {
+		c := &object.Commit{Author: author, Committer: author, Message: tt.message}
+		if got := creditedEmails(c, opts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCoauthorsScored(t *testing.T) {
+	r := testrepo.New(t)
+	sig := testrepo.Sig("author@example.com", testrepo.DaysAgo(0))
+	r.CommitWith(sig, sig, "Pair\n\nCo-authored-by: Ann <ann@example.com>\n", nil)
+	opts := testOptions()
+	_, owners := scan(t, opts, r.Dir)
+	if got := scores(owners); !near(got["author@example.com"], 1) || !near(got["ann@example.com"], 1) {
+		t.Errorf("got %v, want full credit for both", got)
+	}
+}
+
+func TestIdentityModes(t *testing.T) {
+	r := testrepo.New(t)
+	when := testrepo.DaysAgo(0)
+	r.CommitWith(testrepo.Sig("author@example.com", when), testrepo.Sig("maintainer@example.com", when), "", nil)
+	r.CommitWith(testrepo.Sig("maintainer@example.com", when), testrepo.Sig("maintainer@example.com", when), "", nil)
+
+	tests := []struct {
+		identity string
+		want     map[string]float64
+	}{
+		{IdentityAuthor, map[string]float64{"author@example.com": 1, "maintainer@example.com": 1}},
+		{IdentityCommitter, map[string]float64{"maintainer@example.com": 2}},
+		// The committer gets CommitterWeight, but not twice for their own commit
+		{IdentityBoth, map[string]float64{"author@example.com": 1, "maintainer@example.com": 1.5}},
+	}
+	for _, tt := range tests {
+		opts := testOptions()
+		opts.Identity = tt.identity
+		opts.CommitterWeight = 0.5
+		_, owners := scan(t, opts, r.Dir)
+		got := scores(owners)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.identity, got, tt.want)
+			continue
+		}
+		for email, want := range tt.want {
+			if !near(got[email], want) {
+				t.Errorf("%s: %s scored %g, want %g", tt.identity, email, got[email], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package owner
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// lineFactor is the --weight-by-lines multiplier of a commit: the lines it
+// added plus the lines it deleted in the files counted reports true for
+// (see linesInScope). A renamed file counts when either its old or its new
+// path does. Merge commits, and commits whose diff cannot be computed, count
+// as 1 so a merge does not claim the merged work.
+func lineFactor(c *object.Commit, counted func(path string) bool) float64 {
+	if c.NumParents() > 1 {
+		return 1
+	}
+	stats, err := c.Stats()
+	if err != nil {
+		return 1
+	}
+	lines := 0
+	for _, s := range stats {
+		// Stats detects renames and names them "old => new"
+		from, to, renamed := strings.Cut(s.Name, " => ")
+		if !counted(from) && !(renamed && counted(to)) {
+			continue
+		}
+		lines += s.Addition + s.Deletion
+	}
+	return float64(lines)
+}
+
+// linesInScope returns the filter lineFactor applies to a commit's files:
+// when the commit's paths were filtered (by PathPrefixes, IgnorePaths or
+// Extensions), only the paths it was kept for count, so lines changed
+// elsewhere in the same commit add nothing. Paths followed across renames
+// are compared under their latest names.
+func linesInScope(paths []string, filtered bool, renames *renameTracker) func(path string) bool {
+	if !filtered {
+		return func(string) bool { return true }
+	}
+	inScope := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		inScope[path] = struct{}{}
+	}
+	return func(path string) bool {
+		if renames != nil {
+			path = renames.current(path)
+		}
+		_, ok := inScope[path]
+		return ok
+	}
+}
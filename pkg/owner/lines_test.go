@@ -0,0 +1,82 @@
+package owner
+
+import (
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestWeightByLinesFavorsLargeCommits(t *testing.T) {
+	r := testrepo.New(t)
+	// small makes ten one-line commits, large one 100-line commit, all the same day
+	for i := 0; i < 10; i++ {
+		r.Commit("small@example.com", testrepo.DaysAgo(1), map[string]string{"notes.txt": testrepo.Lines(i + 1)})
+	}
+	r.Commit("large@example.com", testrepo.DaysAgo(1), map[string]string{"feature.go": testrepo.Lines(100)})
+
+	opts := testOptions()
+	opts.Decay = DecayNone
+	_, byCommits := scan(t, opts, r.Dir)
+	if got := scores(byCommits); got["small@example.com"] != 10 || got["large@example.com"] != 1 {
+		t.Errorf("by commits: got %v, want small 10, large 1", got)
+	}
+
+	opts.WeightByLines = true
+	_, byLines := scan(t, opts, r.Dir)
+	if got := scores(byLines); got["small@example.com"] != 10 || got["large@example.com"] != 100 {
+		t.Errorf("by lines: got %v, want small 10, large 100", got)
+	}
+	if byLines[0].Email != "large@example.com" {
+		t.Errorf("by lines: %s ranks first, want large@example.com", byLines[0].Email)
+	}
+}
+
+func TestWeightByLinesCountsOnlyScopedFiles(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("dave@example.com", testrepo.DaysAgo(1), map[string]string{
+		"svc/billing/a.go": testrepo.Lines(3),
+		"docs/manual.md":   testrepo.Lines(50),
+	})
+	opts := testOptions()
+	opts.Decay = DecayNone
+	opts.WeightByLines = true
+	opts.PathPrefixes = []string{"svc/billing/"}
+	_, owners := scan(t, opts, r.Dir)
+	if got := scores(owners)["dave@example.com"]; got != 3 {
+		t.Errorf("got score %g, want the 3 lines under svc/billing", got)
+	}
+}
+
+func TestWeightByLinesCountsRenamedFiles(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(3), map[string]string{
+		"old/parser.go": testrepo.Lines(40),
+		"gen/a.pb.go":   testrepo.Lines(40),
+		"gen/b.pb.go":   testrepo.Lines(40),
+	})
+	// Renames with a one-line edit, into and out of the excluded gen/
+	r.Commit("bob@example.com", testrepo.DaysAgo(2), map[string]string{"old/parser.go": "", "new/parser.go": testrepo.Lines(41)})
+	r.Commit("carol@example.com", testrepo.DaysAgo(1), map[string]string{
+		"gen/a.pb.go": "", "gen/c.pb.go": testrepo.Lines(41),
+		"gen/b.pb.go": "", "src/b.go": testrepo.Lines(41),
+	})
+
+	for _, renameScore := range []int{0, 50} {
+		opts := testOptions()
+		opts.Decay = DecayNone
+		opts.WeightByLines = true
+		opts.RenameScore = renameScore
+		opts.PathPrefixes = []string{"new/"}
+		_, owners := scan(t, opts, r.Dir)
+		if got := scores(owners)["bob@example.com"]; got != 1 {
+			t.Errorf("--path, rename score %d: got score %g, want the 1 line edited in the rename", renameScore, got)
+		}
+
+		opts.PathPrefixes = nil
+		opts.IgnorePaths = []string{"gen/"}
+		_, owners = scan(t, opts, r.Dir)
+		if got := scores(owners)["carol@example.com"]; got != 1 {
+			t.Errorf("--exclude-path, rename score %d: got score %g, want the 1 line edited in the rename out of gen/", renameScore, got)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package owner
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Verbosity levels of the diagnostics written to LogOutput (--quiet, --verbose).
+const (
+	LevelError = iota // Nothing but errors, which callers report themselves
+	LevelWarn         // Warnings about skipped or partial input
+	LevelInfo         // Progress messages (the default)
+	LevelDebug        // Per-commit scoring details
+)
+
+var (
+	LogLevel            = LevelInfo // Messages above this level are dropped
+	LogOutput io.Writer = os.Stderr // Diagnostics never go to stdout, which holds the report
+)
+
+// logf writes a diagnostic if level is enabled. The format is used as is, so
+// progress lines can redraw themselves with "\r" instead of ending in "\n".
+func logf(level int, format string, args ...any) {
+	if level > LogLevel {
+		return
+	}
+	fmt.Fprintf(LogOutput, format, args...)
+}
+
+// Warnf reports a problem the run recovered from, such as a skipped repository.
+func Warnf(format string, args ...any) { logf(LevelWarn, format+"\n", args...) }
+
+// Infof reports progress.
+func Infof(format string, args ...any) { logf(LevelInfo, format+"\n", args...) }
+
+// Debugf reports scoring details, such as the weight of every commit.
+func Debugf(format string, args ...any) { logf(LevelDebug, format+"\n", args...) }
@@ -0,0 +1,50 @@
+package owner
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestLogLevels(t *testing.T) {
+	level, output := LogLevel, LogOutput
+	defer func() { LogLevel, LogOutput = level, output }()
+
+	tests := []struct {
+		level int
+		want  string
+	}{
+		{LevelError, ""},
+		{LevelWarn, "warn\n"},
+		{LevelInfo, "warn\ninfo\n"},
+		{LevelDebug, "warn\ninfo\ndebug\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		LogLevel, LogOutput = tt.level, &buf
+		Warnf("warn")
+		Infof("info")
+		Debugf("debug")
+		if got := buf.String(); got != tt.want {
+			t.Errorf("level %d: got %q, want %q", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestProgress(t *testing.T) {
+	level, output := LogLevel, LogOutput
+	defer func() { LogLevel, LogOutput = level, output }()
+	var buf bytes.Buffer
+	LogLevel, LogOutput = LevelInfo, &buf
+
+	r := testrepo.New(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(0), nil)
+	opts := testOptions()
+	opts.Progress = true
+	scan(t, opts, r.Dir, r.Dir)
+	if got := buf.String(); !strings.Contains(got, "Repositories done: 1/2\n") || !strings.Contains(got, "Repositories done: 2/2\n") {
+		t.Errorf("got %q, want a line per repository", got)
+	}
+}
@@ -0,0 +1,93 @@
+package owner
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// mailmapEmail matches the <email> parts of a .mailmap line.
+var mailmapEmail = regexp.MustCompile(`<([^>]*)>`)
+
+// LoadMailmap reads a git .mailmap file into an alias_email -> canonical_email
+// map that composes with LoadAliases. Of the four line forms, only those naming
+// two emails map an identity:
+//
+//	Proper Name <commit@email>                             (name only, ignored)
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+//
+// The commit name of the last form is ignored, so the mapping applies to every
+// commit with that email.
+func LoadMailmap(filePath string) (map[string]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mailmap := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		// A trailing comment may follow the last email
+		if end := strings.LastIndex(text, ">"); end >= 0 {
+			text = text[:end+1]
+		}
+		emails := mailmapEmail.FindAllStringSubmatch(text, -1)
+		switch len(emails) {
+		case 1:
+			continue // Only corrects the name
+		case 2:
+		default:
+			return nil, fmt.Errorf("%s:%d: %w: expected one or two <email> parts", filePath, line, ErrParse)
+		}
+		proper := strings.ToLower(strings.TrimSpace(emails[0][1]))
+		commit := strings.ToLower(strings.TrimSpace(emails[1][1]))
+		if proper == "" || commit == "" || proper == commit {
+			continue
+		}
+		if existing, ok := mailmap[commit]; ok && existing != proper {
+			Warnf("Warning: %s maps '%s' to both '%s' and '%s'. Using '%s'.", filePath, commit, existing, proper, proper)
+		}
+		mailmap[commit] = proper
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mailmap %s: %w", filePath, err)
+	}
+	return mailmap, nil
+}
+
+// MergeMailmap folds mailmap mappings into aliasMap, which takes precedence:
+// when both map the same email to different identities, the alias file wins
+// and a warning is printed. Mailmap targets that are themselves aliases are
+// resolved, so CanonicalEmail still needs a single lookup.
+func MergeMailmap(aliasMap, mailmap map[string]string) {
+	canonicals := make(map[string]struct{})
+	for _, canonical := range aliasMap {
+		canonicals[canonical] = struct{}{}
+	}
+	for commit, proper := range mailmap {
+		proper = CanonicalEmail(proper, aliasMap)
+		if _, ok := canonicals[commit]; ok && commit != proper {
+			Warnf("Warning: '%s' is a canonical email in the aliases file but an alias for '%s' in the mailmap. Keeping it canonical.", commit, proper)
+			continue
+		}
+		if existing, ok := aliasMap[commit]; ok {
+			if existing != proper {
+				Warnf("Warning: '%s' is an alias for '%s' in the aliases file but for '%s' in the mailmap. Using '%s'.", commit, existing, proper, existing)
+			}
+			continue
+		}
+		if commit == proper {
+			continue
+		}
+		aliasMap[commit] = proper
+	}
+}
@@ -0,0 +1,74 @@
+package owner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFile writes content to name in a temporary directory and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMailmapLineForms(t *testing.T) {
+	path := writeFile(t, ".mailmap", `# comment
+Name Only <name@example.com>
+<proper@example.com> <Commit@Example.com>
+Jane Doe <jane@example.com> <jdoe@old.example.com>
+Joe Bloggs <joe@example.com> Joe B <joeb@old.example.com> # trailing comment
+
+`)
+	got, err := LoadMailmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"commit@example.com":   "proper@example.com",
+		"jdoe@old.example.com": "jane@example.com",
+		"joeb@old.example.com": "joe@example.com",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLoadMailmapConflictKeepsLastMapping(t *testing.T) {
+	path := writeFile(t, ".mailmap", "<first@example.com> <shared@example.com>\n<second@example.com> <shared@example.com>\n")
+	got, err := LoadMailmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["shared@example.com"] != "second@example.com" {
+		t.Errorf("got %v, want shared@example.com mapped to second@example.com", got)
+	}
+}
+
+func TestLoadMailmapRejectsMalformedLines(t *testing.T) {
+	path := writeFile(t, ".mailmap", "<a@example.com> <b@example.com> <c@example.com>\n")
+	if _, err := LoadMailmap(path); !errors.Is(err, ErrParse) {
+		t.Errorf("got %v, want ErrParse", err)
+	}
+}
+
+func TestMergeMailmapAliasFileWins(t *testing.T) {
+	aliases := map[string]string{"old@example.com": "alias-owner@example.com"}
+	MergeMailmap(aliases, map[string]string{
+		"old@example.com":   "mailmap-owner@example.com",
+		"other@example.com": "old@example.com", // Resolved through the alias file
+	})
+	want := map[string]string{
+		"old@example.com":   "alias-owner@example.com",
+		"other@example.com": "alias-owner@example.com",
+	}
+	if !reflect.DeepEqual(aliases, want) {
+		t.Errorf("got %v, want %v", aliases, want)
+	}
+}
@@ -0,0 +1,98 @@
+package owner
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// normalizeName folds an author name for --merge-by-name: case and runs of
+// whitespace are ignored.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// NameAliases is the first pass of --merge-by-name: it counts the commits
+// credited to each canonical identity under each author name, and groups the
+// identities whose most used names are equal after normalizeName. Every
+// group with more than one identity is merged into its most used email (ties
+// go to the alphabetically first). It returns the merged identities mapped to
+// their group's email, for AddAliases.
+//
+// Identities are canonical emails, so explicit aliases and mailmaps apply
+// first. Each identity joins only the group of its most used name, so a
+// shared alternate spelling cannot chain unrelated groups together.
+// Repositories that fail to open are skipped here and reported by the
+// scoring pass.
+func NameAliases(repoPaths []string, opts ScanOptions) map[string]string {
+	counts := make(map[string]map[string]int) // canonical email -> normalized name -> commits
+	for _, repoPath := range repoPaths {
+		repo, head, err := OpenRepo(repoPath, opts.Ref)
+		if err != nil {
+			continue
+		}
+		iter, err := repo.Log(&git.LogOptions{From: head})
+		if err != nil {
+			continue
+		}
+		_ = iter.ForEach(func(c *object.Commit) error {
+			for _, cr := range commitCredits(c, opts) {
+				name := normalizeName(cr.Sig.Name)
+				if name == "" {
+					continue
+				}
+				if _, ok := counts[cr.CanonicalEmail]; !ok {
+					counts[cr.CanonicalEmail] = make(map[string]int)
+				}
+				counts[cr.CanonicalEmail][name]++
+			}
+			return nil
+		})
+	}
+
+	groups := make(map[string][]string) // normalized name -> canonical emails
+	total := make(map[string]int)       // canonical email -> commits
+	for email, names := range counts {
+		best, bestCount := "", 0
+		for name, n := range names {
+			total[email] += n
+			if n > bestCount || (n == bestCount && name < best) {
+				best, bestCount = name, n
+			}
+		}
+		groups[best] = append(groups[best], email)
+	}
+
+	merged := make(map[string]string)
+	for _, emails := range groups {
+		if len(emails) < 2 {
+			continue
+		}
+		sort.Slice(emails, func(i, j int) bool {
+			if total[emails[i]] != total[emails[j]] {
+				return total[emails[i]] > total[emails[j]]
+			}
+			return emails[i] < emails[j]
+		})
+		for _, email := range emails[1:] {
+			merged[email] = emails[0]
+		}
+	}
+	return merged
+}
+
+// AddAliases folds extra (canonical email -> new canonical email) into
+// aliasMap. Aliases pointing at a merged email are redirected to its new
+// canonical, so CanonicalEmail still needs a single lookup.
+func AddAliases(aliasMap, extra map[string]string) {
+	for alias, canonical := range aliasMap {
+		if target, ok := extra[canonical]; ok {
+			aliasMap[alias] = target
+		}
+	}
+	for email, canonical := range extra {
+		aliasMap[email] = canonical
+	}
+}
@@ -0,0 +1,43 @@
+package owner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestMergeByName(t *testing.T) {
+	r := testrepo.New(t)
+	commit := func(name, email string) {
+		sig := &object.Signature{Name: name, Email: email, When: testrepo.DaysAgo(0)}
+		r.CommitWith(sig, sig, "", nil)
+	}
+	commit("Jane Doe", "jane@work.example.com")
+	commit("Jane Doe", "jane@work.example.com")
+	commit("Jane Doe", "jane@work.example.com")
+	commit("jane  DOE", "jane@laptop.local")
+	commit("Jane Doe", "old@example.com") // Explicitly aliased to the laptop address
+	commit("John Roe", "john@example.com")
+
+	opts := testOptions()
+	opts.AliasMap = map[string]string{"old@example.com": "jane@laptop.local"}
+	merged := NameAliases([]string{r.Dir}, opts)
+	if want := map[string]string{"jane@laptop.local": "jane@work.example.com"}; !reflect.DeepEqual(merged, want) {
+		t.Fatalf("got %v, want %v", merged, want)
+	}
+
+	AddAliases(opts.AliasMap, merged)
+	_, owners := scan(t, opts, r.Dir)
+	if len(owners) != 2 {
+		t.Fatalf("got %+v, want jane and john", owners)
+	}
+	jane := owners[0]
+	if jane.Email != "jane@work.example.com" || !near(jane.Score, 5) || !reflect.DeepEqual(jane.AliasesUsed, []string{"jane@laptop.local", "old@example.com"}) {
+		t.Errorf("got %+v, want jane@work.example.com with 5 commits and both aliases", jane)
+	}
+	if john := owners[1]; john.Email != "john@example.com" || len(john.AliasesUsed) != 0 {
+		t.Errorf("got %+v, want john@example.com unmerged", john)
+	}
+}
@@ -0,0 +1,53 @@
+package owner
+
+import "strings"
+
+// DefaultNormalizeDomains are the providers whose mailboxes ignore dots and
+// +tags in the local part (--normalize-gmail).
+var DefaultNormalizeDomains = []string{"gmail.com", "googlemail.com"}
+
+// NormalizeProviderEmail lowercases email and, if its domain is in domains,
+// drops the dots and any +tag from its local part, so john.doe+work@gmail.com
+// becomes johndoe@gmail.com. Other addresses are only lowercased.
+func NormalizeProviderEmail(email string, domains map[string]struct{}) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	if _, ok := domains[domain]; !ok {
+		return email
+	}
+	local, _, _ = strings.Cut(local, "+")
+	local = strings.ReplaceAll(local, ".", "")
+	if local == "" {
+		return email // Nothing left to identify the mailbox
+	}
+	return local + "@" + domain
+}
+
+// ResolveEmail returns the canonical email of an identity. An explicit alias
+// of the email as written wins; otherwise its provider-normalized form is
+// looked up. Canonical emails are normalized too, so an alias target and
+// unaliased commits from the same mailbox meet under one key. With no
+// domains this is CanonicalEmail.
+func ResolveEmail(email string, aliasMap map[string]string, domains map[string]struct{}) string {
+	if len(domains) == 0 {
+		return CanonicalEmail(email, aliasMap)
+	}
+	normalizedEmail := strings.ToLower(strings.TrimSpace(email))
+	if canonical, ok := aliasMap[normalizedEmail]; ok {
+		return NormalizeProviderEmail(canonical, domains)
+	}
+	normalizedEmail = NormalizeProviderEmail(normalizedEmail, domains)
+	if canonical, ok := aliasMap[normalizedEmail]; ok {
+		return NormalizeProviderEmail(canonical, domains)
+	}
+	return normalizedEmail
+}
+
+// canonicalEmail resolves an email with the scan's aliases and normalized domains.
+func (o ScanOptions) canonicalEmail(email string) string {
+	return ResolveEmail(email, o.AliasMap, o.NormalizeDomains)
+}
@@ -0,0 +1,43 @@
+package owner
+
+import "testing"
+
+func TestNormalizeProviderEmail(t *testing.T) {
+	domains := map[string]struct{}{"gmail.com": {}, "googlemail.com": {}}
+	tests := map[string]string{
+		"john.doe@gmail.com":         "johndoe@gmail.com",
+		"johndoe+work@gmail.com":     "johndoe@gmail.com",
+		"John.Doe+Work@GMail.com":    "johndoe@gmail.com",
+		"j.o.h.n.doe@googlemail.com": "johndoe@googlemail.com",
+		"john.doe+x@example.com":     "john.doe+x@example.com", // Not a listed provider
+		"+tag@gmail.com":             "+tag@gmail.com",         // Nothing left of the local part
+		"no-at-sign":                 "no-at-sign",
+	}
+	for email, want := range tests {
+		if got := NormalizeProviderEmail(email, domains); got != want {
+			t.Errorf("NormalizeProviderEmail(%q) = %q, want %q", email, got, want)
+		}
+	}
+}
+
+func TestResolveEmailExplicitAliasWins(t *testing.T) {
+	domains := map[string]struct{}{"gmail.com": {}}
+	aliases := map[string]string{
+		"john.doe+ci@gmail.com": "ci@example.com",   // Matches the address as written
+		"johndoe@gmail.com":     "john@example.com", // Matches any normalized form
+	}
+	tests := map[string]string{
+		"John.Doe+CI@gmail.com":   "ci@example.com",
+		"john.doe+work@gmail.com": "john@example.com",
+		"JohnDoe@gmail.com":       "john@example.com",
+		"jane.doe@gmail.com":      "janedoe@gmail.com",
+	}
+	for email, want := range tests {
+		if got := ResolveEmail(email, aliases, domains); got != want {
+			t.Errorf("ResolveEmail(%q) = %q, want %q", email, got, want)
+		}
+	}
+	if got := ResolveEmail("john.doe@gmail.com", aliases, nil); got != "john.doe@gmail.com" {
+		t.Errorf("without normalization got %q, want the address unchanged", got)
+	}
+}
@@ -0,0 +1,1054 @@
+package owner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings" // Needed for string manipulation
+	"time"
+
+	"github.com/BurntSushi/toml" // Import TOML library
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	// "golang.org/x/exp/maps" // No longer strictly necessary if not using maps.Keys
+)
+
+// OwnerScore represents a user and their score
+type OwnerScore struct {
+	Email          string    `json:"email" yaml:"email"`
+	Name           string    `json:"name" yaml:"name"` // Most frequently used author name for this email
+	Score          float64   `json:"score" yaml:"score"`
+	RepoCount      int       `json:"repo_count" yaml:"repo_count"`
+	CommitCount    int       `json:"commit_count" yaml:"commit_count"`
+	ActiveDays     int       `json:"active_days" yaml:"active_days"` // Distinct calendar days with a counted commit
+	RawScore       float64   `json:"raw_score" yaml:"raw_score"`
+	BonusFactor    float64   `json:"bonus_factor" yaml:"bonus_factor"`                     // Cross-repository bonus: Score is RawScore * BonusFactor (before --relative-to)
+	AliasesUsed    []string  `json:"aliases_used,omitempty" yaml:"aliases_used,omitempty"` // Optional: To show which aliases were merged
+	TicketRefs     int       `json:"ticket_refs" yaml:"ticket_refs"`                       // Ticket references found in this owner's commit messages
+	HomeRepo       string    `json:"home_repo,omitempty" yaml:"home_repo,omitempty"`       // Repository where this owner has the highest decayed score
+	LastActive     time.Time `json:"last_active" yaml:"last_active"`                       // Time of this owner's most recent counted commit
+	LastActiveDays int       `json:"last_active_days" yaml:"last_active_days"`             // Whole days between LastActive and the reference time
+	ScoreLow       float64   `json:"score_low" yaml:"score_low"`                           // Lower bound of the ~95% interval (equals Score when not sampling)
+	ScoreHigh      float64   `json:"score_high" yaml:"score_high"`                         // Upper bound of the ~95% interval (equals Score when not sampling)
+	GitHubLogin    string    `json:"github_login,omitempty" yaml:"github_login,omitempty"` // Set by the gitowner command's --github-resolve
+}
+
+// Data accumulates per-user data across all processed repositories.
+// All maps are keyed by canonical email.
+type Data struct {
+	Scores     map[string]float64             // canonical_email -> Accumulated base score
+	repos      map[string]map[string]struct{} // canonical_email -> Set of repo paths contributed to
+	aliases    map[string]map[string]struct{} // canonical_email -> Set of alias emails used for this canonical
+	names      map[string]map[string]int      // canonical_email -> author name -> number of commits using it
+	commits    map[string]int                 // canonical_email -> Number of commits counted
+	days       map[string]map[string]struct{} // canonical_email -> Set of calendar days with a counted commit
+	vars       map[string]float64             // canonical_email -> Estimated variance of the score (sampling only)
+	Files      map[FileKey]map[string]float64 // file -> canonical_email -> Accumulated weight (only with TrackFiles)
+	tickets    map[string]int                 // canonical_email -> Ticket references in commit messages
+	seen       map[plumbing.Hash]struct{}     // Commits already scored (only with DedupAcrossRepos)
+	Scored     int                            // Commits that credited at least one identity
+	Invalid    map[string]int                 // raw malformed email -> Credits seen
+	CommitLog  []CommitRecord                 // Every credit, in walk order (only with RecordCommits)
+	RepoScores map[string]map[string]float64  // canonical_email -> repo path -> Accumulated base score in that repo
+	LastActive map[string]time.Time           // canonical_email -> Most recent commit time
+	activeDays map[string]map[string]float64  // canonical_email -> calendar day -> Best weight credited that day (--weight-by=active-days)
+	Skipped    []SkippedRepo                  // Repositories ScanRepos could not process, in scan order
+	AgeCounts  map[string][]int               // canonical_email -> Commits per AgeBuckets bucket, oldest last (only with AgeBuckets)
+}
+
+// SkippedRepo is a repository skipped by ScanRepos and the reason why.
+type SkippedRepo struct {
+	Repo  string `json:"repo"`
+	Error string `json:"error"`
+}
+
+// FileKey identifies a file within one of the analyzed repositories.
+type FileKey struct {
+	Repo string
+	Path string
+}
+
+func NewData() *Data {
+	return &Data{
+		Scores:     make(map[string]float64),
+		repos:      make(map[string]map[string]struct{}),
+		aliases:    make(map[string]map[string]struct{}),
+		names:      make(map[string]map[string]int),
+		commits:    make(map[string]int),
+		days:       make(map[string]map[string]struct{}),
+		vars:       make(map[string]float64),
+		Files:      make(map[FileKey]map[string]float64),
+		tickets:    make(map[string]int),
+		seen:       make(map[plumbing.Hash]struct{}),
+		RepoScores: make(map[string]map[string]float64),
+		LastActive: make(map[string]time.Time),
+		activeDays: make(map[string]map[string]float64),
+		Invalid:    make(map[string]int),
+		AgeCounts:  make(map[string][]int),
+	}
+}
+
+// --- Structure for the TOML Aliases File ---
+type AliasConfig struct {
+	Aliases map[string][]string `toml:"aliases"` // canonical_email -> [alias1, alias2, ...]
+}
+
+// --- Function to load and process aliases ---
+func LoadAliases(filePath string) (map[string]string, error) {
+	aliasMap := make(map[string]string) // Final map: alias_email -> canonical_email
+	if filePath == "" {
+		return aliasMap, nil // No file provided, return empty map
+	}
+
+	Infof("Attempting to load aliases from: %s", filePath)
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		// If the file doesn't exist, it's not necessarily a fatal error if the flag was optional
+		if os.IsNotExist(err) {
+			Warnf("Warning: Alias file not found at %s, proceeding without aliases.", filePath)
+			return aliasMap, nil // Return empty map, not an execution error
+		}
+		return nil, fmt.Errorf("failed to read alias file %s: %w", filePath, err)
+	}
+
+	var config AliasConfig
+	if _, err := toml.Decode(string(data), &config); err != nil {
+		return nil, fmt.Errorf("failed to parse alias file %s: %w: %w", filePath, ErrParse, err)
+	}
+
+	// Invert the map for quick lookup: alias -> canonical
+	duplicates := make(map[string]string) // To detect if an alias points to multiple canonicals
+	for canonical, aliasList := range config.Aliases {
+		canonical = strings.ToLower(strings.TrimSpace(canonical)) // Normalize canonical
+		if canonical == "" {
+			continue
+		} // Ignore empty entries
+
+		// Ensure the canonical is not already an alias for another
+		if existingCanonical, isAlias := aliasMap[canonical]; isAlias {
+			Warnf("Warning: Canonical email '%s' is already listed as an alias for '%s'. Check your aliases file.", canonical, existingCanonical)
+			// Decide how to handle this, here we just ignore it as canonical if it's already an alias.
+			continue
+		}
+
+		for _, alias := range aliasList {
+			alias = strings.ToLower(strings.TrimSpace(alias)) // Normalize alias
+			if alias == "" || alias == canonical {
+				continue
+			} // Ignore empty aliases or those identical to the canonical
+
+			if existingCanonical, exists := aliasMap[alias]; exists {
+				// This alias was already mapped to another canonical!
+				if existingCanonical != canonical {
+					Warnf("Warning: Alias '%s' is mapped to multiple canonical emails ('%s' and '%s'). Using '%s'. Check your aliases file.", alias, existingCanonical, canonical, canonical)
+					// We could decide to keep the first, the last, or error out. Here we overwrite (last one wins).
+				}
+				duplicates[alias] = canonical // Register the conflict (last one wins)
+			}
+			// Check if an email listed as an alias is also listed as a canonical email itself
+			if _, isAlsoCanonical := config.Aliases[alias]; isAlsoCanonical {
+				Warnf("Warning: Email '%s' is listed both as an alias (for '%s') and as a canonical email itself. Using it as an alias.", alias, canonical)
+			}
+			aliasMap[alias] = canonical
+		}
+	}
+	// Apply detected duplicates (last one wins)
+	for alias, canonical := range duplicates {
+		aliasMap[alias] = canonical
+	}
+
+	Infof("Loaded %d alias mappings.", len(aliasMap))
+	return aliasMap, nil
+}
+
+// --- Function to get the canonical email ---
+func CanonicalEmail(email string, aliasMap map[string]string) string {
+	normalizedEmail := strings.ToLower(strings.TrimSpace(email))
+	if canonical, ok := aliasMap[normalizedEmail]; ok {
+		return canonical // Returns the mapped canonical email
+	}
+	return normalizedEmail // Returns the original (normalized) email if it's not an alias
+}
+
+// ScanOptions holds the settings that control how individual commits are scored.
+type ScanOptions struct {
+	Tau              float64
+	Decay            string    // Recency function applied with Tau: exponential (default), linear, step or none
+	Now              time.Time // Reference time commit ages are measured from
+	AliasMap         map[string]string
+	NormalizeDomains map[string]struct{} // Email domains whose local parts ignore dots and +tags (see NormalizeProviderEmail)
+	ExcludeRanges    []DateRange         // Commits authored within any of these bands are dropped
+	Since            time.Time           // If set, commits authored before this are dropped
+	Until            time.Time           // If set, commits authored at or after this are dropped
+	SampleRate       float64             // Fraction of commits to score (1 = all); sampled weights are scaled up by 1/SampleRate
+	Seed             uint64              // Seeds every probabilistic decision (currently commit sampling)
+	PathPrefixes     []string            // If set, only commits changing a file under one of these prefixes are scored
+	IgnorePaths      []string            // gitignore-style patterns of files that never count; commits touching only those are dropped
+	Extensions       []string            // If set, only files with these extensions (see NormalizeExtension) count; other commits are dropped
+	ScaleByExtension bool                // Scale a commit by the fraction of its changed files matching Extensions
+	Identity         string              // Which identities are credited: author, committer or both
+	CommitterWeight  float64             // Fraction of a commit's weight credited to its committer in "both" mode
+	CoauthorWeight   float64             // Fraction of a commit's weight credited to each Co-authored-by trailer (0 = ignore trailers)
+	TrackFiles       bool                // Record per-file weights in Data.Files (requires diffing every commit)
+	TicketPattern    *regexp.Regexp      // Matches ticket references in commit messages; nil disables parsing
+	TicketBonus      float64             // Weight boost per distinct referenced ticket
+	DedupAcrossRepos bool                // Score each commit hash once even if several repositories contain it
+	DiscountReverts  bool                // Discount reverts and commits whose net effect was reverted
+	RevertWeight     float64             // Weight multiplier for commits discounted by DiscountReverts
+	MaintenanceBonus float64             // Boost per year of average age of the touched files
+	Strict           bool                // Fail the whole repository on a commit walk error instead of keeping partial results
+	FullBlame        bool                // Score lines of the HEAD tree via blame instead of walking commits
+	BlameWorkers     int                 // Number of files blamed concurrently in FullBlame mode
+	Progress         bool                // Redraw a progress line on LogOutput: commits walked or files blamed, and repositories done
+	BlameCache       bool                // Reuse blame results of unchanged files from earlier runs
+	CacheDir         string              // If set, reuse each repository's commit walk from here while its HEAD and the scoring options are unchanged
+	ReleaseBonus     float64             // Boost for commits close to a tagged release
+	ReleaseWindow    time.Duration       // How close to a release a commit must be for ReleaseBonus
+	SignedBonus      float64             // Boost for commits whose signature verifies against Keyring
+	Keyring          string              // ASCII-armored public keyring for signature verification
+	Candidates       map[string]struct{} // If non-nil, only these canonical emails are scored (--top-k-precise)
+	WeightBy         string              // What a unit of ownership is: commits or active-days
+	InvalidEmails    string              // Policy for malformed emails: keep, bucket or exclude
+	Dependents       DependencyMap       // If non-nil, changed files weigh 1 + ln(dependents) (--deps-file)
+	ExcludeEmails    map[string]struct{} // Commits whose primary identity has one of these canonical emails are dropped
+	ExcludePatterns  []*regexp.Regexp    // Commits whose primary identity's email or name matches one of these are dropped
+	ExcludeBots      bool                // Drop commits by well-known bot and CI accounts
+	RecordCommits    bool                // Keep a per-commit credit log (--sqlite-out)
+	AllBranches      bool                // Walk every local and remote-tracking branch, not just HEAD
+	WeightFloor      float64             // Minimum recency factor of any in-scope commit or line (0 = pure decay)
+	ReleasedOnly     bool                // Walk only history reachable from a tag (falls back to HEAD without tags)
+	Ref              string              // Branch, tag or commit hash scored instead of HEAD (empty = HEAD)
+	WeightByLines    bool                // Multiply each commit's weight by its lines added plus deleted (diffs every commit)
+	RepoTau          map[string]float64  // repo path -> Tau override for that repository
+	OriginBonus      float64             // Undecayed weight credited to the identities of each repository's earliest scored commit
+	SignedOnly       bool                // Drop commits without a PGP signature
+	VerifySignatures bool                // With SignedOnly, also drop commits whose signature does not verify against Keyring
+	MaxFutureSkew    time.Duration       // Commits dated further than this after Now are reported (0 = no check); nearer ones just count as brand new
+	DropFutureDated  bool                // Drop the commits caught by MaxFutureSkew instead of counting them as brand new
+	RepoWeight       map[string]float64  // repo path -> Multiplier of every weight credited in that repository (missing = 1)
+	RawCount         bool                // Credit every counted commit with weight 1, ignoring decay, RepoWeight and every other factor
+	AgeBuckets       []float64           // Increasing upper bounds, in days, of the commit age buckets counted in Data.AgeCounts (commit walks only)
+	ExcludeMessages  []*regexp.Regexp    // Commits whose message matches one of these are dropped (commit walks only)
+	RenameScore      int                 // If positive, follow files renamed with at least this percent similarity, crediting older commits to the latest path
+	AllRefs          bool                // With AllBranches, also walk history reachable only from tags
+	NoDetectDotGit   bool                // Scan each path exactly as given instead of the repository containing it (see ResolveRepoRoots)
+
+	// OnCommit, if set, is called for every scored commit of a commit walk
+	// (not FullBlame) with the total weight credited for it, after the commit
+	// is recorded. An error stops the scan: the repository keeps what was
+	// scored up to and including that commit, and the remaining ones are
+	// skipped. Functions cannot be marshaled, so it is left out of the scan
+	// cache key.
+	OnCommit CommitFunc `json:"-"`
+}
+
+// CommitFunc is the type of ScanOptions.OnCommit.
+type CommitFunc func(repo string, c *object.Commit, weight float64) error
+
+// repoWeight returns the RepoWeight multiplier of a repository.
+func (o ScanOptions) repoWeight(repoPath string) float64 {
+	if w, ok := o.RepoWeight[repoPath]; ok {
+		return w
+	}
+	return 1
+}
+
+// CommitRecord is one credit of one commit, kept for --sqlite-out.
+type CommitRecord struct {
+	Hash   string
+	Repo   string
+	Email  string // Canonical email credited
+	Name   string
+	When   time.Time
+	Weight float64
+	Paths  []string // Changed paths (in scope)
+}
+
+// OpenRepo opens the repository containing repoPath (which may be one of its
+// subdirectories) and resolves rev (a branch, tag or commit hash; HEAD if
+// empty) to a commit, wrapping failures in the matching sentinel error.
+func OpenRepo(repoPath, rev string) (*git.Repository, plumbing.Hash, error) {
+	repo, err := openRepository(repoPath)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, plumbing.ZeroHash, fmt.Errorf("failed to open repository %s: %w", repoPath, ErrRepoNotFound)
+	}
+	if err != nil {
+		return nil, plumbing.ZeroHash, fmt.Errorf("failed to open repository %s: %w", repoPath, err)
+	}
+
+	if rev != "" {
+		hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+		if err != nil {
+			return nil, plumbing.ZeroHash, fmt.Errorf("failed to resolve %q in repository %s: %w: %w", rev, repoPath, ErrRefNotFound, err)
+		}
+		return repo, *hash, nil
+	}
+	ref, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		// Unborn HEAD: an empty repo or one without commits
+		return nil, plumbing.ZeroHash, fmt.Errorf("failed to get HEAD for repository %s: %w", repoPath, ErrEmptyRepo)
+	}
+	if err != nil {
+		return nil, plumbing.ZeroHash, fmt.Errorf("failed to get HEAD for repository %s: %w: %w", repoPath, ErrNoHead, err)
+	}
+	return repo, ref.Hash(), nil
+}
+
+// progressEvery is how many commits are walked between Progress updates.
+const progressEvery = 500
+
+// processRepoCommits analyzes a single repository and updates the global maps.
+// Returns an error if it cannot process the repository. If ctx is cancelled
+// mid-walk, the commits scored so far are kept.
+func processRepoCommits(ctx context.Context, repoPath string, opts ScanOptions, data *Data) error {
+	Infof("Processing repository: %s", repoPath)
+	repo, head, err := OpenRepo(repoPath, opts.Ref)
+	if err != nil {
+		return err
+	}
+
+	// A cached walk of the same state under the same options replaces the walk
+	var cacheFile, state string
+	if opts.scanCacheable() {
+		cacheFile, err = scanCacheFile(repoPath, opts)
+		if err == nil {
+			state, err = scanState(repo, head, opts)
+		}
+		if err != nil {
+			Warnf("Warning: not caching %s: %v", repoPath, err)
+			cacheFile = ""
+		} else if cached, ok := loadScanCache(cacheFile, repoPath, state, opts); ok {
+			data.merge(cached)
+			Infof("Finished processing %s (from cache).", repoPath)
+			return nil
+		}
+	}
+
+	// Released history only: walk from every tag instead of HEAD
+	var tips []plumbing.Hash
+	if opts.ReleasedOnly {
+		tips, err = releaseTips(repo)
+		if err != nil {
+			return fmt.Errorf("failed to list tags in %s: %w", repoPath, err)
+		}
+		if len(tips) == 0 {
+			Warnf("Warning: %s has no tags; --released-only falls back to scoring all history.", repoPath)
+		}
+	}
+	if len(tips) == 0 && opts.AllBranches {
+		// One walk over the union of all branches, so shared history is scored once
+		tips, err = branchTips(repo, head, opts.AllRefs)
+		if err != nil {
+			return fmt.Errorf("failed to list refs of repository %s: %w", repoPath, err)
+		}
+	}
+	if len(tips) == 0 {
+		tips = []plumbing.Hash{head}
+	}
+	commitIter, err := walkFrom(repo, tips)
+	if err != nil {
+		return fmt.Errorf("failed to get commit log for repository %s: %w", repoPath, err)
+	}
+
+	// Revert analysis needs the whole history up front, so it is a separate pass
+	var discounted map[plumbing.Hash]struct{}
+	if opts.DiscountReverts {
+		discounted, err = discountedByReverts(repo, tips)
+		if err != nil {
+			return fmt.Errorf("failed to analyze reverts in %s: %w", repoPath, err)
+		}
+	}
+
+	// File ages need every file's creation time, so they also need a pre-pass
+	var history *fileHistory
+	if opts.MaintenanceBonus > 0 {
+		history, err = loadFileHistory(repo, tips, opts.Identity)
+		if err != nil {
+			return fmt.Errorf("failed to load file history for %s: %w", repoPath, err)
+		}
+	}
+
+	// Tagged releases act as anchors: work close to a release gets a boost
+	var releases []time.Time
+	if opts.ReleaseBonus > 0 {
+		releases, err = releaseTimes(repo)
+		if err != nil {
+			return fmt.Errorf("failed to list tags in %s: %w", repoPath, err)
+		}
+	}
+
+	now := opts.Now
+	decay := opts.decayer()
+	repoWeight := opts.repoWeight(repoPath)
+	ignore := ignoreMatcher(opts.IgnorePaths)
+
+	// Results go into a per-repository accumulator first, so a repository whose
+	// walk fails can be discarded as a whole under --strict
+	repoData := NewData()
+	var lastHash plumbing.Hash // Last commit processed successfully
+	future := 0                // Commits caught by MaxFutureSkew
+	var originWhen time.Time   // Date of the earliest scored commit (for OriginBonus)
+	var origin []credit        // Its credits
+	// Changed paths are only needed to filter commits or credit files
+	needPaths := len(opts.PathPrefixes) > 0 || ignore != nil || len(opts.Extensions) > 0 || opts.TrackFiles || history != nil || opts.Dependents != nil
+	var renames *renameTracker
+	if opts.RenameScore > 0 && needPaths {
+		renames = newRenameTracker(opts.RenameScore)
+	}
+
+	scoreCommit := func(c *object.Commit) error {
+		if c == nil {
+			return nil
+		}
+		// Renames are followed for every commit, even those filtered out below
+		var followed []string
+		if renames != nil {
+			var err error
+			if followed, err = renames.follow(c); err != nil {
+				return fmt.Errorf("failed to compute changed files for commit %s: %w", c.Hash, err)
+			}
+		}
+		// The primary signature decides the commit's date for filtering
+		primary := c.Author
+		if opts.Identity == IdentityCommitter {
+			primary = c.Committer
+		}
+		// Ignore commits with zero time (can happen with merges/errors)
+		if primary.When.IsZero() {
+			return nil
+		}
+
+		// A commit from the far future has a wrong clock; its age is clamped to zero unless it is dropped
+		if opts.futureDated(primary.When) {
+			future++
+			if opts.DropFutureDated {
+				return nil
+			}
+		}
+
+		// Keep only the sampled subset of commits (deterministic per hash)
+		if opts.SampleRate < 1 && !sampled(c.Hash, opts.SampleRate, opts.Seed) {
+			return nil
+		}
+
+		// Drop commits outside --since/--until or inside an excluded time band (e.g. a mass-migration day)
+		if opts.timeExcluded(primary.When) {
+			return nil
+		}
+
+		// Drop commits by excluded contributors (bots, the --remove simulation, ...)
+		if opts.signatureExcluded(primary) {
+			return nil
+		}
+
+		// Drop automated commits recognized by their message ("chore: bump version", ...)
+		if opts.messageExcluded(c.Message) {
+			return nil
+		}
+
+		// Only signed (or verified) commits count as evidence of ownership
+		if opts.SignedOnly && !opts.signatureTrusted(c) {
+			Debugf("%s %s: dropped, not signed or not verified", repoPath, c.Hash.String()[:12])
+			return nil
+		}
+
+		// Changed files are only computed when needed, since diffing every commit is expensive
+		var paths []string
+		extensionFactor := 1.0
+		if needPaths {
+			if renames != nil {
+				paths = followed
+			} else if history != nil {
+				paths = history.paths[c.Hash]
+			} else {
+				var err error
+				paths, err = changedPaths(c)
+				if err != nil {
+					return fmt.Errorf("failed to compute changed files for commit %s: %w", c.Hash, err)
+				}
+			}
+			// Restrict scoring to commits touching the requested paths
+			if len(opts.PathPrefixes) > 0 {
+				paths = pathsInScope(paths, opts.PathPrefixes)
+				if len(paths) == 0 {
+					return nil
+				}
+			}
+			// Commits touching only ignored files (vendored code, lockfiles, ...) carry no weight
+			if ignore != nil {
+				paths = withoutIgnored(paths, ignore)
+				if len(paths) == 0 {
+					return nil
+				}
+			}
+			// Scope ownership to file types: only matching files count
+			if len(opts.Extensions) > 0 {
+				matching := opts.withExtension(paths)
+				if len(matching) == 0 {
+					return nil
+				}
+				if opts.ScaleByExtension {
+					extensionFactor = float64(len(matching)) / float64(len(paths))
+				}
+				paths = matching
+			}
+		}
+
+		// Reverted work and the reverts themselves only keep RevertWeight
+		revertFactor := 1.0
+		if _, ok := discounted[c.Hash]; ok {
+			revertFactor = opts.RevertWeight
+		}
+
+		// Commits whose signature verifies against the keyring are trusted more
+		signedFactor := 1.0
+		if opts.SignedBonus > 0 && signatureVerified(c, opts.Keyring) {
+			signedFactor = 1 + opts.SignedBonus
+		}
+
+		releaseFactor := 1.0
+		if len(releases) > 0 && nearRelease(primary.When, releases, opts.ReleaseWindow) {
+			releaseFactor = 1 + opts.ReleaseBonus
+		}
+
+		// Commits referencing tracked work get a small boost per distinct ticket
+		tickets := 0
+		if opts.TicketPattern != nil {
+			tickets = countTickets(c.Message, opts.TicketPattern)
+		}
+
+		// A commit shared by several repositories is scored only the first time it is
+		// seen; later sightings only record repository membership for RepoCount.
+		if opts.DedupAcrossRepos {
+			if _, dup := data.seen[c.Hash]; dup {
+				for _, cr := range commitCredits(c, opts) {
+					repoData.addRepo(cr.CanonicalEmail, repoPath)
+				}
+				return nil
+			}
+			repoData.seen[c.Hash] = struct{}{}
+		}
+
+		// Edits to older files (maintenance) weigh more than greenfield work
+		maintenanceFactor := 1.0
+		if history != nil {
+			maintenanceFactor = history.maintenanceFactor(paths, primary.When, opts.MaintenanceBonus)
+		}
+
+		// Changing widely imported files is higher-stakes ownership
+		blastFactor := opts.Dependents.commitFactor(paths)
+
+		// A 500-line feature outweighs a one-line typo fix
+		linesFactor := 1.0
+		if opts.WeightByLines {
+			linesFactor = lineFactor(c, linesInScope(paths, needPaths, renames))
+		}
+
+		var credited []credit
+		total := 0.0 // Weight credited for the commit across identities
+		for _, cr := range commitCredits(c, opts) {
+			var ok bool
+			if cr.CanonicalEmail, ok = repoData.screenEmail(cr.Sig.Email, cr.CanonicalEmail, opts.InvalidEmails); !ok {
+				continue
+			}
+			// With --top-k-precise only the pre-selected candidates are tracked
+			if opts.Candidates != nil {
+				if _, ok := opts.Candidates[cr.CanonicalEmail]; !ok {
+					continue
+				}
+			}
+			daysAgo := now.Sub(cr.Sig.When).Hours() / 24
+			// Ensure daysAgo is not negative (in case of clock skew)
+			if daysAgo < 0 {
+				daysAgo = 0
+			}
+			weight := decay(daysAgo) * repoWeight * cr.Factor * revertFactor * maintenanceFactor * releaseFactor * signedFactor * blastFactor * linesFactor * extensionFactor * (1 + opts.TicketBonus*float64(tickets))
+			if opts.RawCount {
+				weight = 1
+			}
+			if opts.WeightBy == WeightByActiveDays {
+				// Only a day's best commit counts, so many small commits equal one large one
+				weight = repoData.claimActiveDay(data, cr.CanonicalEmail, cr.Sig.When, weight)
+			}
+			variance := 0.0
+			if opts.SampleRate < 1 {
+				// Horvitz-Thompson estimate: scale up by the inverse inclusion probability
+				weight /= opts.SampleRate
+				variance = weight * weight * (1 - opts.SampleRate)
+			}
+			Debugf("%s %s %s %s: %.4f (age %.0fd)", repoPath, c.Hash.String()[:12], cr.Sig.When.Format(time.DateOnly), cr.CanonicalEmail, weight, daysAgo)
+			repoData.record(repoPath, cr.Sig, cr.CanonicalEmail, weight, variance)
+			repoData.tickets[cr.CanonicalEmail] += tickets
+			if len(opts.AgeBuckets) > 0 {
+				repoData.countAge(cr.CanonicalEmail, opts.ageBucket(daysAgo), len(opts.AgeBuckets)+1)
+			}
+			if opts.TrackFiles {
+				repoData.recordFiles(repoPath, paths, cr.CanonicalEmail, weight)
+			}
+			if opts.RecordCommits {
+				repoData.CommitLog = append(repoData.CommitLog, CommitRecord{
+					Hash: c.Hash.String(), Repo: repoPath, Email: cr.CanonicalEmail, Name: cr.Sig.Name,
+					When: cr.Sig.When, Weight: weight, Paths: paths,
+				})
+			}
+			credited = append(credited, cr)
+			total += weight
+		}
+		if len(credited) > 0 {
+			repoData.Scored++
+			if opts.OnCommit != nil {
+				if err := opts.OnCommit(repoPath, c, total); err != nil {
+					return err
+				}
+			}
+			// Ties go to the commit walked later, which is the older one
+			if opts.OriginBonus > 0 && (originWhen.IsZero() || !primary.When.After(originWhen)) {
+				originWhen, origin = primary.When, credited
+			}
+		}
+		return nil
+	}
+
+	walked := 0
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := scoreCommit(c); err != nil {
+			return err
+		}
+		lastHash = c.Hash
+		if walked++; opts.Progress && walked%progressEvery == 0 {
+			logf(LevelInfo, "\rCommits walked: %d", walked)
+		}
+		return nil
+	})
+	if opts.Progress && walked >= progressEvery {
+		logf(LevelInfo, "\rCommits walked: %d\n", walked)
+	}
+	opts.warnFutureDated(repoPath, future)
+	// Whoever started the repository (or the scope, with --path) keeps credit for it, however long ago
+	for _, cr := range origin {
+		weight := opts.OriginBonus * repoWeight * cr.Factor
+		Debugf("%s origin %s: %.4f", repoPath, cr.CanonicalEmail, weight)
+		repoData.Scores[cr.CanonicalEmail] += weight
+		repoData.RepoScores[cr.CanonicalEmail][repoPath] += weight
+	}
+	if err != nil && ctx.Err() != nil {
+		Warnf("Warning: scan of %s interrupted (%v). Keeping partial results.", repoPath, context.Cause(ctx))
+	} else if err != nil {
+		// A missing object in a shallow clone means the walk hit the shallow boundary
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			if shallow, _ := repo.Storer.Shallow(); len(shallow) > 0 {
+				err = fmt.Errorf("%w: %w", ErrShallow, err)
+			}
+		}
+		if opts.Strict {
+			return fmt.Errorf("error iterating commits in %s: %w", repoPath, err)
+		}
+		// Keep what was gathered before the failure (e.g. a corrupt or mid-gc repository)
+		if lastHash.IsZero() {
+			Warnf("Warning: error iterating commits in %s before any commit was processed: %v", repoPath, err)
+		} else {
+			Warnf("Warning: error iterating commits in %s after commit %s: %v. Keeping partial results (use --strict to skip the repository instead).", repoPath, lastHash, err)
+		}
+		if errors.Is(err, ErrShallow) {
+			Warnf("Hint: run 'git fetch --unshallow' in %s to analyze its full history.", repoPath)
+		}
+	}
+
+	// Only complete walks are cached
+	if cacheFile != "" && err == nil {
+		if err := saveScanCache(cacheFile, repoPath, state, now, repoData); err != nil {
+			Warnf("Warning: could not write scan cache for %s: %v", repoPath, err)
+		}
+	}
+	data.merge(repoData)
+	Infof("Finished processing %s.", repoPath)
+	return nil // Success for this repository
+}
+
+// record credits one commit to a canonical user.
+func (d *Data) record(repoPath string, sig object.Signature, canonicalEmail string, weight, variance float64) {
+	originalNormalized := strings.ToLower(strings.TrimSpace(sig.Email))
+
+	d.Scores[canonicalEmail] += weight // Use the canonical email as the key
+	if _, ok := d.RepoScores[canonicalEmail]; !ok {
+		d.RepoScores[canonicalEmail] = make(map[string]float64)
+	}
+	d.RepoScores[canonicalEmail][repoPath] += weight
+	d.vars[canonicalEmail] += variance
+	d.commits[canonicalEmail]++
+	if _, ok := d.days[canonicalEmail]; !ok {
+		d.days[canonicalEmail] = make(map[string]struct{})
+	}
+	d.days[canonicalEmail][sig.When.Format(activeDayLayout)] = struct{}{}
+	if sig.When.After(d.LastActive[canonicalEmail]) {
+		d.LastActive[canonicalEmail] = sig.When
+	}
+
+	// Record that this (canonical) user contributed to this repo
+	d.addRepo(canonicalEmail, repoPath)
+
+	// Record the name so the most common one can be displayed
+	if name := strings.TrimSpace(sig.Name); name != "" {
+		if _, ok := d.names[canonicalEmail]; !ok {
+			d.names[canonicalEmail] = make(map[string]int)
+		}
+		d.names[canonicalEmail][name]++
+	}
+
+	// Record which alias was used for this canonical user (if it was different from the canonical)
+	if originalNormalized != canonicalEmail {
+		if _, ok := d.aliases[canonicalEmail]; !ok {
+			d.aliases[canonicalEmail] = make(map[string]struct{})
+		}
+		d.aliases[canonicalEmail][originalNormalized] = struct{}{}
+	}
+}
+
+// merge folds another accumulator (typically one repository's results) into d.
+func (d *Data) merge(o *Data) {
+	for email, score := range o.Scores {
+		d.Scores[email] += score
+	}
+	for email, repos := range o.repos {
+		for repo := range repos {
+			d.addRepo(email, repo)
+		}
+	}
+	for email, aliases := range o.aliases {
+		if _, ok := d.aliases[email]; !ok {
+			d.aliases[email] = make(map[string]struct{})
+		}
+		for alias := range aliases {
+			d.aliases[email][alias] = struct{}{}
+		}
+	}
+	for email, names := range o.names {
+		if _, ok := d.names[email]; !ok {
+			d.names[email] = make(map[string]int)
+		}
+		for name, n := range names {
+			d.names[email][name] += n
+		}
+	}
+	for email, repoScores := range o.RepoScores {
+		if _, ok := d.RepoScores[email]; !ok {
+			d.RepoScores[email] = make(map[string]float64)
+		}
+		for repo, score := range repoScores {
+			d.RepoScores[email][repo] += score
+		}
+	}
+	for email, days := range o.activeDays {
+		if _, ok := d.activeDays[email]; !ok {
+			d.activeDays[email] = make(map[string]float64)
+		}
+		for day, w := range days {
+			d.activeDays[email][day] = max(d.activeDays[email][day], w)
+		}
+	}
+	for email, t := range o.LastActive {
+		if t.After(d.LastActive[email]) {
+			d.LastActive[email] = t
+		}
+	}
+	for email, n := range o.commits {
+		d.commits[email] += n
+	}
+	for email, days := range o.days {
+		if _, ok := d.days[email]; !ok {
+			d.days[email] = make(map[string]struct{})
+		}
+		for day := range days {
+			d.days[email][day] = struct{}{}
+		}
+	}
+	for email, v := range o.vars {
+		d.vars[email] += v
+	}
+	for key, weights := range o.Files {
+		if _, ok := d.Files[key]; !ok {
+			d.Files[key] = make(map[string]float64)
+		}
+		for email, w := range weights {
+			d.Files[key][email] += w
+		}
+	}
+	for email, n := range o.tickets {
+		d.tickets[email] += n
+	}
+	for email, counts := range o.AgeCounts {
+		if _, ok := d.AgeCounts[email]; !ok {
+			d.AgeCounts[email] = make([]int, len(counts))
+		}
+		for i, n := range counts {
+			d.AgeCounts[email][i] += n
+		}
+	}
+	for hash := range o.seen {
+		d.seen[hash] = struct{}{}
+	}
+	d.Scored += o.Scored
+	for email, n := range o.Invalid {
+		d.Invalid[email] += n
+	}
+	d.CommitLog = append(d.CommitLog, o.CommitLog...)
+}
+
+// addRepo records that a canonical user contributed to a repository.
+func (d *Data) addRepo(canonicalEmail, repoPath string) {
+	if _, ok := d.repos[canonicalEmail]; !ok {
+		d.repos[canonicalEmail] = make(map[string]struct{})
+	}
+	d.repos[canonicalEmail][repoPath] = struct{}{}
+}
+
+// recordFiles credits a commit's weight to every file it changed.
+func (d *Data) recordFiles(repoPath string, paths []string, canonicalEmail string, weight float64) {
+	for _, path := range paths {
+		key := FileKey{Repo: repoPath, Path: path}
+		if _, ok := d.Files[key]; !ok {
+			d.Files[key] = make(map[string]float64)
+		}
+		d.Files[key][canonicalEmail] += weight
+	}
+}
+
+// mostUsedName returns the name used most often, breaking ties alphabetically.
+func mostUsedName(names map[string]int) string {
+	best, bestCount := "", 0
+	for name, n := range names {
+		if n > bestCount || (n == bestCount && name < best) {
+			best, bestCount = name, n
+		}
+	}
+	return best
+}
+
+// MakeRelative divides every owner's score by the baseline owner's score, so
+// the baseline shows 1.0 and everyone else their ratio to it.
+func MakeRelative(owners []OwnerScore, baselineEmail string) error {
+	baseScore := 0.0
+	found := false
+	for _, owner := range owners {
+		if owner.Email == baselineEmail {
+			baseScore, found = owner.Score, true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("baseline contributor %s has no commits in the analyzed scope", baselineEmail)
+	}
+	if baseScore <= 0 {
+		return fmt.Errorf("baseline contributor %s has a zero score", baselineEmail)
+	}
+	for i := range owners {
+		owners[i].Score /= baseScore
+		owners[i].ScoreLow /= baseScore
+		owners[i].ScoreHigh /= baseScore
+	}
+	return nil
+}
+
+// homeRepo returns the repository with the highest score, breaking ties by path.
+func homeRepo(repoScores map[string]float64) string {
+	best, bestScore := "", -1.0
+	for repo, score := range repoScores {
+		if score > bestScore || (score == bestScore && repo < best) {
+			best, bestScore = repo, score
+		}
+	}
+	return best
+}
+
+// RankOptions holds the settings applied when turning accumulated data into a ranking.
+type RankOptions struct {
+	BonusPerRepo float64
+	RepoBonus    map[string]float64 // repo path -> BonusPerRepo override for that repository
+	Now          time.Time          // Reference time for LastActiveDays and pruning
+	PruneStale   time.Duration      // Drop owners whose last commit is older than this (0 keeps everyone)
+	MinScore     float64            // Drop owners whose final score is below this (0 keeps everyone)
+	MinRepos     int                // Drop owners active in fewer repositories than this (0 keeps everyone)
+	SortBy       string             // Primary sort key: SortScore (default, also ""), SortRepos, SortRecent or SortEmail
+}
+
+// RankOwners builds the ranking sorted by SortBy and drops stale owners and
+// those below MinScore or MinRepos. It returns the ranking and the number of
+// owners pruned.
+func RankOwners(data *Data, rank RankOptions) ([]OwnerScore, int) {
+	owners := buildOwners(data, rank.BonusPerRepo, rank.RepoBonus, rank.Now)
+	SortOwners(owners, rank.SortBy)
+	if rank.PruneStale <= 0 && rank.MinScore <= 0 && rank.MinRepos <= 0 {
+		return owners, 0
+	}
+	cutoff := rank.Now.Add(-rank.PruneStale)
+	kept := owners[:0]
+	for _, owner := range owners {
+		if rank.PruneStale > 0 && owner.LastActive.Before(cutoff) {
+			continue
+		}
+		if owner.Score < rank.MinScore || owner.RepoCount < rank.MinRepos {
+			continue
+		}
+		kept = append(kept, owner)
+	}
+	return kept, len(owners) - len(kept)
+}
+
+// buildOwners converts the accumulated data into an unsorted OwnerScore slice, applying the bonus.
+func buildOwners(data *Data, bonusPerRepo float64, repoBonus map[string]float64, now time.Time) []OwnerScore {
+	owners := make([]OwnerScore, 0, len(data.Scores))
+	for canonicalEmail, rawScore := range data.Scores {
+		repoSet := data.repos[canonicalEmail] // The set of repos for this user
+		repoCount := len(repoSet)
+
+		aliasesSet := data.aliases[canonicalEmail] // The set of aliases used for this canonical email
+		aliases := make([]string, 0, len(aliasesSet))
+		for alias := range aliasesSet {
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases) // Sort for consistent output
+
+		// Calculate the bonus factor
+		// If contributed to 1 repo, repoCount = 1, bonus = 1.0 + (1-1)*rate = 1.0
+		// If contributed to 2 repos, repoCount = 2, bonus = 1.0 + (2-1)*rate = 1.0 + rate
+		// If contributed to 3 repos, repoCount = 3, bonus = 1.0 + (3-1)*rate = 1.0 + 2*rate
+		bonusFactor := 1.0
+		if repoCount > 1 && len(repoBonus) == 0 {
+			bonusFactor = 1.0 + (float64(repoCount-1) * bonusPerRepo)
+		} else if repoCount > 1 {
+			// With per-repository rates, every repository but the home one adds its own rate
+			home := homeRepo(data.RepoScores[canonicalEmail])
+			for repo := range repoSet {
+				if repo == home {
+					continue
+				}
+				rate, ok := repoBonus[repo]
+				if !ok {
+					rate = bonusPerRepo
+				}
+				bonusFactor += rate
+			}
+		}
+
+		finalScore := rawScore * bonusFactor
+
+		// Margin of error for sampled scores; zero when every commit was scored
+		margin := scoreMargin(data.vars[canonicalEmail], data.commits[canonicalEmail]) * bonusFactor
+
+		owners = append(owners, OwnerScore{
+			Email:          canonicalEmail, // Always use the canonical email
+			Name:           mostUsedName(data.names[canonicalEmail]),
+			Score:          finalScore,
+			RepoCount:      repoCount,
+			CommitCount:    data.commits[canonicalEmail],
+			ActiveDays:     len(data.days[canonicalEmail]),
+			TicketRefs:     data.tickets[canonicalEmail],
+			HomeRepo:       homeRepo(data.RepoScores[canonicalEmail]),
+			LastActive:     data.LastActive[canonicalEmail],
+			LastActiveDays: int(math.Max(0, now.Sub(data.LastActive[canonicalEmail]).Hours()/24)),
+			RawScore:       rawScore, // Store the raw score for potential debugging/info
+			AliasesUsed:    aliases,  // Save the aliases that were merged into this one
+			ScoreLow:       math.Max(0, finalScore-margin),
+			ScoreHigh:      finalScore + margin,
+			BonusFactor:    bonusFactor,
+		})
+	}
+	return owners
+}
+
+// TopN returns at most count owners from the front of the sorted slice.
+func TopN(owners []OwnerScore, count int) []OwnerScore {
+	if len(owners) < count {
+		return owners
+	}
+	return owners[:count]
+}
+
+// ScanRepos processes every repository into a fresh accumulator, warning about
+// (and skipping) repositories that cannot be processed. It returns the paths
+// of the skipped repositories alongside the data, whose Skipped field also
+// holds each one's error. Repositories without any
+// commit are not skipped: they just add nothing. Once ctx is cancelled the
+// repository being scanned keeps its partial results and the remaining ones
+// are skipped; an error from opts.OnCommit does the same. Unless
+// opts.NoDetectDotGit is set, a path inside a working tree is replaced by its
+// top level (see RepoRoot), so the data is keyed by each repository's top
+// level.
+func ScanRepos(ctx context.Context, repoPaths []string, opts ScanOptions) (*Data, []string) {
+	data := NewData()
+	var failed []string
+	if onCommit := opts.OnCommit; onCommit != nil {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+		opts.OnCommit = func(repo string, c *object.Commit, weight float64) error {
+			err := onCommit(repo, c, weight)
+			if err != nil {
+				cancel(err)
+			}
+			return err
+		}
+	}
+	if !opts.NoDetectDotGit {
+		roots := make([]string, len(repoPaths))
+		for i, path := range repoPaths {
+			roots[i] = topLevel(path)
+		}
+		repoPaths = roots
+	}
+	// Iterate over each provided repository path
+	for i, repoPath := range repoPaths {
+		if ctx.Err() != nil {
+			err := context.Cause(ctx)
+			Warnf("Warning: Skipping %d remaining repositories: %v", len(repoPaths)-i, err)
+			failed = append(failed, repoPaths[i:]...)
+			for _, rest := range repoPaths[i:] {
+				data.Skipped = append(data.Skipped, SkippedRepo{Repo: rest, Error: err.Error()})
+			}
+			break
+		}
+		// Pass the scan options and the accumulator to the processing function
+		process := processRepoCommits
+		if opts.FullBlame {
+			process = processRepoBlame
+		}
+		repoOpts := opts
+		if tau, ok := opts.RepoTau[repoPath]; ok {
+			repoOpts.Tau = tau
+		}
+		var err error
+		if opts.NoDetectDotGit {
+			// OpenRepo would walk up from a subdirectory, so only a top level is accepted here
+			if _, err = git.PlainOpen(repoPath); errors.Is(err, git.ErrRepositoryNotExists) {
+				err = fmt.Errorf("failed to open repository %s: %w", repoPath, ErrRepoNotFound)
+			}
+		}
+		if err == nil {
+			err = process(ctx, repoPath, repoOpts, data)
+		}
+		if errors.Is(err, ErrEmptyRepo) {
+			// A freshly initialized repository (or HEAD on an unborn branch) simply has no owners yet
+			Infof("Note: HEAD of %s has no commits yet; it contributes no owners.", repoPath)
+		} else if err != nil {
+			// Print a warning if a repo fails, but continue with the others
+			failed = append(failed, repoPath)
+			data.Skipped = append(data.Skipped, SkippedRepo{Repo: repoPath, Error: err.Error()})
+			Warnf("Warning: Skipping repository %s due to error: %v", repoPath, err)
+			if errors.Is(err, ErrShallow) {
+				Warnf("Hint: run 'git fetch --unshallow' in %s to analyze its full history.", repoPath)
+			}
+		}
+		if opts.Progress {
+			logf(LevelInfo, "Repositories done: %d/%d\n", i+1, len(repoPaths))
+		}
+	}
+	return data, failed
+}
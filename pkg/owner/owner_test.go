@@ -0,0 +1,276 @@
+package owner
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestMain(m *testing.M) {
+	LogLevel = LevelError // Progress messages would drown the test output
+	os.Exit(m.Run())
+}
+
+// testOptions returns the scan options of a default gitowner run as of
+// testrepo.Now.
+func testOptions() ScanOptions {
+	return ScanOptions{
+		Tau:            365,
+		Now:            testrepo.Now,
+		AliasMap:       map[string]string{},
+		SampleRate:     1,
+		Identity:       IdentityAuthor,
+		CoauthorWeight: 1,
+		WeightBy:       WeightByCommits,
+		InvalidEmails:  InvalidEmailsKeep,
+	}
+}
+
+// scan scans repos and ranks the owners with no cross-repository bonus.
+func scan(t *testing.T, opts ScanOptions, repos ...string) (*Data, []OwnerScore) {
+	t.Helper()
+	data, failed := ScanRepos(context.Background(), repos, opts)
+	if len(failed) > 0 {
+		t.Fatalf("scan failed for %v", failed)
+	}
+	owners, _ := RankOwners(data, RankOptions{Now: opts.Now})
+	for i := range owners {
+		owners[i].LastActive = owners[i].LastActive.UTC() // Cached times lose their location
+	}
+	return data, owners
+}
+
+// scores returns the owners' scores by email.
+func scores(owners []OwnerScore) map[string]float64 {
+	m := make(map[string]float64, len(owners))
+	for _, o := range owners {
+		m[o.Email] = o.Score
+	}
+	return m
+}
+
+// near reports whether a and b are equal up to rounding.
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+// budgetContext is cancelled once Err has been called budget times: the
+// scan checks it before each repository and each commit.
+type budgetContext struct {
+	context.Context
+	budget int
+}
+
+func (c *budgetContext) Err() error {
+	if c.budget--; c.budget < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestCancelStopsWalk(t *testing.T) {
+	r := testrepo.New(t)
+	for i := 0; i < 20; i++ {
+		r.Commit("alice@example.com", testrepo.DaysAgo(0), nil)
+	}
+	other := testrepo.New(t)
+	other.Commit("bob@example.com", testrepo.DaysAgo(0), nil)
+
+	// Cancelled after the first repository's check and 5 commits
+	ctx := &budgetContext{Context: context.Background(), budget: 1 + 5}
+	data, failed := ScanRepos(ctx, []string{r.Dir, other.Dir}, testOptions())
+	if !near(data.Scores["alice@example.com"], 5) {
+		t.Errorf("got partial score %g, want 5", data.Scores["alice@example.com"])
+	}
+	if len(failed) != 1 || failed[0] != other.Dir {
+		t.Errorf("got skipped %v, want only %s", failed, other.Dir)
+	}
+}
+
+func TestMinScoreAndMinRepos(t *testing.T) {
+	a := testrepo.New(t)
+	a.Commit("alice@example.com", testrepo.DaysAgo(0), nil)
+	a.Commit("alice@example.com", testrepo.DaysAgo(0), nil)
+	a.Commit("bob@example.com", testrepo.DaysAgo(0), nil)
+	a.Commit("carol@example.com", testrepo.DaysAgo(365), nil) // Scores 1/e
+	b := testrepo.New(t)
+	b.Commit("carol@example.com", testrepo.DaysAgo(365), nil)
+	opts := testOptions()
+	data, _ := scan(t, opts, a.Dir, b.Dir)
+
+	tests := []struct {
+		rank RankOptions
+		want []string
+		drop int
+	}{
+		{RankOptions{Now: opts.Now, MinScore: 1}, []string{"alice@example.com", "bob@example.com"}, 1}, // Bob is right at the threshold
+		{RankOptions{Now: opts.Now, MinScore: 0.7}, []string{"alice@example.com", "bob@example.com", "carol@example.com"}, 0},
+		{RankOptions{Now: opts.Now, MinScore: 1.01}, []string{"alice@example.com"}, 2},
+		{RankOptions{Now: opts.Now, MinRepos: 2}, []string{"carol@example.com"}, 2},
+	}
+	for _, tt := range tests {
+		owners, dropped := RankOwners(data, tt.rank)
+		var got []string
+		for _, o := range owners {
+			got = append(got, o.Email)
+		}
+		if !reflect.DeepEqual(got, tt.want) || dropped != tt.drop {
+			t.Errorf("%+v: got %v and %d dropped, want %v and %d", tt.rank, got, dropped, tt.want, tt.drop)
+		}
+	}
+}
+
+func TestEmptyRepos(t *testing.T) {
+	empty := testrepo.New(t)
+	unborn := testrepo.New(t)
+	unborn.Commit("alice@example.com", testrepo.DaysAgo(0), nil)
+	head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("empty"))
+	if err := unborn.Repo.Storer.SetReference(head); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{empty.Dir, unborn.Dir} {
+		if _, _, err := OpenRepo(dir, ""); !errors.Is(err, ErrEmptyRepo) {
+			t.Errorf("OpenRepo(%s): got %v, want ErrEmptyRepo", dir, err)
+		}
+	}
+	// scan fails the test if either repository is skipped
+	if _, owners := scan(t, testOptions(), empty.Dir, unborn.Dir); len(owners) != 0 {
+		t.Errorf("got owners %+v, want none", owners)
+	}
+}
+
+func TestDedupAcrossRepos(t *testing.T) {
+	// Identical commits give identical hashes, so fork shares the history of r
+	r, fork := testrepo.New(t), testrepo.New(t)
+	for _, repo := range []*testrepo.Repo{r, fork} {
+		repo.Commit("alice@example.com", testrepo.DaysAgo(0), nil)
+		repo.Commit("alice@example.com", testrepo.DaysAgo(0), nil)
+	}
+	fork.Commit("bob@example.com", testrepo.DaysAgo(0), nil)
+
+	tests := []struct {
+		dedup     bool
+		repos     []string
+		alice     float64
+		repoCount int
+	}{
+		{false, []string{r.Dir, r.Dir}, 4, 1},
+		{true, []string{r.Dir, r.Dir}, 2, 1},
+		{false, []string{r.Dir, fork.Dir}, 4, 2},
+		{true, []string{r.Dir, fork.Dir}, 2, 2}, // Both repositories still count for RepoCount
+	}
+	for _, tt := range tests {
+		opts := testOptions()
+		opts.DedupAcrossRepos = tt.dedup
+		_, owners := scan(t, opts, tt.repos...)
+		got := scores(owners)
+		if !near(got["alice@example.com"], tt.alice) || owners[0].RepoCount != tt.repoCount {
+			t.Errorf("dedup %v over %d repositories: got %+v, want alice at %g in %d", tt.dedup, len(tt.repos), owners, tt.alice, tt.repoCount)
+		}
+		if tt.repos[1] == fork.Dir && !near(got["bob@example.com"], 1) {
+			t.Errorf("dedup %v: bob scored %g, want 1", tt.dedup, got["bob@example.com"])
+		}
+	}
+}
+
+func TestOriginBonus(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("founder@example.com", testrepo.DaysAgo(700), map[string]string{"README.md": "hi\n"})
+	r.Commit("late@example.com", testrepo.DaysAgo(300), map[string]string{"lib/a.go": "a\n"})
+	r.Commit("late@example.com", testrepo.DaysAgo(5), nil)
+
+	_, plain := scan(t, testOptions(), r.Dir)
+	opts := testOptions()
+	opts.OriginBonus = 2
+	_, boosted := scan(t, opts, r.Dir)
+	before, after := scores(plain), scores(boosted)
+	if !near(after["founder@example.com"], before["founder@example.com"]+2) {
+		t.Errorf("founder: got %g, want %g plus an undecayed 2", after["founder@example.com"], before["founder@example.com"])
+	}
+	if !near(after["late@example.com"], before["late@example.com"]) {
+		t.Errorf("late contributor: got %g, want %g unchanged", after["late@example.com"], before["late@example.com"])
+	}
+
+	// With --path, the origin is the first commit in scope
+	opts.PathPrefixes = []string{"lib/"}
+	_, scoped := scan(t, opts, r.Dir)
+	if got := scores(scoped); len(got) != 1 || !near(got["late@example.com"], math.Exp(-300.0/365)+2) {
+		t.Errorf("--path lib/: got %v, want only late@example.com with the bonus", got)
+	}
+}
+
+func TestCommitCountAndActiveDays(t *testing.T) {
+	r := testrepo.New(t)
+	for day := 3; day <= 4; day++ {
+		for hour := 0; hour < 5; hour++ {
+			r.Commit("heavy@example.com", testrepo.DaysAgo(float64(day)).Add(time.Duration(hour)*time.Hour), nil)
+		}
+		r.Commit("light@example.com", testrepo.DaysAgo(float64(day)), nil)
+	}
+	_, owners := scan(t, testOptions(), r.Dir)
+	got := make(map[string][2]int)
+	for _, o := range owners {
+		got[o.Email] = [2]int{o.CommitCount, o.ActiveDays}
+	}
+	want := map[string][2]int{"heavy@example.com": {10, 2}, "light@example.com": {2, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got commits and active days %v, want %v", got, want)
+	}
+}
+
+func TestRepoWeight(t *testing.T) {
+	core, docs := testrepo.New(t), testrepo.New(t)
+	core.Commit("alice@example.com", testrepo.DaysAgo(10), nil)
+	core.Commit("bob@example.com", testrepo.DaysAgo(20), nil)
+	docs.Commit("bob@example.com", testrepo.DaysAgo(5), nil)
+	docs.Commit("carol@example.com", testrepo.DaysAgo(1), nil)
+
+	plain, _ := scan(t, testOptions(), core.Dir, docs.Dir)
+	opts := testOptions()
+	opts.RepoWeight = map[string]float64{core.Dir: 2}
+	weighted, _ := scan(t, opts, core.Dir, docs.Dir)
+	for email, repos := range plain.RepoScores {
+		for repo, score := range repos {
+			want := score
+			if repo == core.Dir {
+				want *= 2
+			}
+			if got := weighted.RepoScores[email][repo]; !near(got, want) {
+				t.Errorf("%s in %s: got %g, want %g", email, repo, got, want)
+			}
+		}
+		want := plain.Scores[email] + plain.RepoScores[email][core.Dir]
+		if got := weighted.Scores[email]; !near(got, want) {
+			t.Errorf("%s: got total %g, want %g", email, got, want)
+		}
+	}
+}
+
+func TestBareCloneScoresLikeWorkTree(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(100), nil)
+	r.Commit("bob@example.com", testrepo.DaysAgo(10), nil)
+	r.Commit("alice@example.com", testrepo.DaysAgo(1), nil)
+	bare := t.TempDir()
+	if _, err := git.PlainClone(bare, true, &git.CloneOptions{URL: r.Dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, want := scan(t, testOptions(), r.Dir)
+	_, got := scan(t, testOptions(), bare)
+	for i := range got {
+		got[i].HomeRepo = r.Dir
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bare clone: got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,252 @@
+package owner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// RootFilesBucket is the pseudo path prefix matching files at the repository root.
+const RootFilesBucket = "(root files)"
+
+// changedPaths returns the paths a commit modified relative to its first parent.
+// For a root commit every file in its tree counts as changed.
+func changedPaths(c *object.Commit) ([]string, error) {
+	changes, err := commitChanges(c, nil)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(changes))
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" { // Deletion
+			name = change.From.Name
+		}
+		paths = append(paths, name)
+	}
+	return paths, nil
+}
+
+// commitChanges diffs a commit against its first parent (or the empty tree
+// for a root commit). With nil opts renames are not detected.
+func commitChanges(c *object.Commit, opts *object.DiffTreeOptions) (object.Changes, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	if opts == nil {
+		return object.DiffTree(parentTree, tree)
+	}
+	return object.DiffTreeWithOptions(context.Background(), parentTree, tree, opts)
+}
+
+// DiffPaths returns the files that differ between the trees of the two refs in
+// spec ("base..branch"), collected over every repository in which both refs
+// resolve. It fails if the refs resolve in none of them.
+func DiffPaths(repoPaths []string, spec string) ([]string, error) {
+	base, branch, ok := strings.Cut(spec, "..")
+	if !ok || base == "" || branch == "" || strings.HasPrefix(branch, ".") {
+		return nil, fmt.Errorf("%w: invalid diff %q (expected base..branch)", ErrParse, spec)
+	}
+	set := make(map[string]struct{})
+	resolved := false
+	for _, repoPath := range repoPaths {
+		repo, err := openRepository(repoPath)
+		if err != nil {
+			continue // processRepoCommits reports the error later
+		}
+		baseTree, err := revisionTree(repo, base)
+		if err != nil {
+			continue
+		}
+		branchTree, err := revisionTree(repo, branch)
+		if err != nil {
+			continue
+		}
+		changes, err := object.DiffTree(baseTree, branchTree)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s in %s: %w", spec, repoPath, err)
+		}
+		resolved = true
+		for _, change := range changes {
+			if change.From.Name != "" {
+				set[change.From.Name] = struct{}{}
+			}
+			if change.To.Name != "" {
+				set[change.To.Name] = struct{}{}
+			}
+		}
+	}
+	if !resolved {
+		return nil, fmt.Errorf("could not resolve both %q and %q in any repository", base, branch)
+	}
+	paths := make([]string, 0, len(set))
+	for path := range set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// revisionTree resolves a revision (branch, tag, hash, ...) to its tree.
+func revisionTree(repo *git.Repository, revision string) (*object.Tree, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+// PathInScope reports whether path falls under prefix. A prefix ending in "/"
+// matches everything below that directory; any other prefix matches that exact
+// file or directory. The RootFilesBucket prefix matches files that are not
+// inside any directory.
+func PathInScope(path, prefix string) bool {
+	if prefix == RootFilesBucket {
+		return !strings.Contains(path, "/")
+	}
+	if strings.HasSuffix(prefix, "/") {
+		return strings.HasPrefix(path, prefix)
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// ignoreMatcher compiles IgnorePaths, written in gitignore syntax
+// ("vendor/**", "*.lock"), into a matcher; nil when there are no patterns.
+func ignoreMatcher(patterns []string) gitignore.Matcher {
+	if len(patterns) == 0 {
+		return nil
+	}
+	ps := make([]gitignore.Pattern, len(patterns))
+	for i, pattern := range patterns {
+		ps[i] = gitignore.ParsePattern(pattern, nil)
+	}
+	return gitignore.NewMatcher(ps)
+}
+
+// pathIgnored reports whether path matches the ignore patterns.
+func pathIgnored(ignore gitignore.Matcher, path string) bool {
+	return ignore != nil && ignore.Match(strings.Split(path, "/"), false)
+}
+
+// withoutIgnored returns the paths that do not match the ignore patterns.
+func withoutIgnored(paths []string, ignore gitignore.Matcher) []string {
+	if ignore == nil {
+		return paths
+	}
+	var kept []string
+	for _, path := range paths {
+		if !pathIgnored(ignore, path) {
+			kept = append(kept, path)
+		}
+	}
+	return kept
+}
+
+// pathsInScope returns the paths that fall under at least one of the prefixes.
+func pathsInScope(paths, prefixes []string) []string {
+	var inScope []string
+	for _, path := range paths {
+		for _, prefix := range prefixes {
+			if PathInScope(path, prefix) {
+				inScope = append(inScope, path)
+				break
+			}
+		}
+	}
+	return inScope
+}
+
+// TopLevelScopes lists the top-level directories (as "dir/" prefixes) found in
+// the trees at rev (HEAD if empty) of the given repositories, followed by
+// RootFilesBucket if any repository has files at its root.
+func TopLevelScopes(repoPaths []string, rev string) []string {
+	dirs := make(map[string]struct{})
+	hasRootFiles := false
+	for _, repoPath := range repoPaths {
+		repo, head, err := OpenRepo(repoPath, rev)
+		if err != nil {
+			continue // processRepoCommits reports the error later
+		}
+		commit, err := repo.CommitObject(head)
+		if err != nil {
+			continue
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			continue
+		}
+		for _, entry := range tree.Entries {
+			if entry.Mode == filemode.Dir {
+				dirs[entry.Name+"/"] = struct{}{}
+			} else {
+				hasRootFiles = true
+			}
+		}
+	}
+
+	scopes := make([]string, 0, len(dirs)+1)
+	for dir := range dirs {
+		scopes = append(scopes, dir)
+	}
+	sort.Strings(scopes)
+	if hasRootFiles {
+		scopes = append(scopes, RootFilesBucket)
+	}
+	return scopes
+}
+
+// NormalizeExtension turns an --ext value such as ".GO", "*.go" or "go" into
+// the lowercase suffix "go". Multi-part extensions like "d.ts" are kept whole.
+func NormalizeExtension(ext string) string {
+	ext = strings.TrimSpace(ext)
+	ext = strings.TrimPrefix(ext, "*")
+	return strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
+// hasExtension reports whether path ends in one of the Extensions; with no
+// Extensions every path matches.
+func (o ScanOptions) hasExtension(path string) bool {
+	if len(o.Extensions) == 0 {
+		return true
+	}
+	lower := strings.ToLower(path)
+	for _, ext := range o.Extensions {
+		if strings.HasSuffix(lower, "."+ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// withExtension returns the paths ending in one of the Extensions.
+func (o ScanOptions) withExtension(paths []string) []string {
+	var kept []string
+	for _, path := range paths {
+		if o.hasExtension(path) {
+			kept = append(kept, path)
+		}
+	}
+	return kept
+}
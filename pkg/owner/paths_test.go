@@ -0,0 +1,121 @@
+package owner
+
+import (
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestPathInScope(t *testing.T) {
+	tests := []struct {
+		path, prefix string
+		want         bool
+	}{
+		{"svc/billing/a.go", "svc/billing/", true},
+		{"svc/billing/a.go", "svc/billing", true},
+		{"svc/billing-v2/a.go", "svc/billing", false},
+		{"svc/billing-v2/a.go", "svc/billing/", false},
+		{"README.md", "README.md", true},
+		{"README.md", RootFilesBucket, true},
+		{"svc/a.go", RootFilesBucket, false},
+	}
+	for _, tt := range tests {
+		if got := PathInScope(tt.path, tt.prefix); got != tt.want {
+			t.Errorf("PathInScope(%q, %q) = %v, want %v", tt.path, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestPathPrefixesPickOwnerPerDirectory(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("billing@example.com", testrepo.DaysAgo(10), map[string]string{"svc/billing/a.go": "a\n"})
+	r.Commit("billing@example.com", testrepo.DaysAgo(9), map[string]string{"svc/billing/b.go": "b\n"})
+	r.Commit("search@example.com", testrepo.DaysAgo(8), map[string]string{"svc/search/a.go": "a\n"})
+	r.Commit("docs@example.com", testrepo.DaysAgo(7), map[string]string{"docs/index.md": "docs\n"})
+
+	for prefix, want := range map[string]string{
+		"svc/billing/": "billing@example.com",
+		"svc/search/":  "search@example.com",
+		"docs/":        "docs@example.com",
+	} {
+		opts := testOptions()
+		opts.PathPrefixes = []string{prefix}
+		_, owners := scan(t, opts, r.Dir)
+		if len(owners) != 1 || owners[0].Email != want {
+			t.Errorf("--path %s: got %+v, want only %s", prefix, owners, want)
+		}
+	}
+
+	opts := testOptions()
+	opts.PathPrefixes = []string{"svc/"}
+	_, owners := scan(t, opts, r.Dir)
+	if len(owners) != 2 || owners[0].Email != "billing@example.com" {
+		t.Errorf("--path svc/: got %+v, want billing then search", owners)
+	}
+}
+
+func TestIgnorePaths(t *testing.T) {
+	ignore := ignoreMatcher([]string{"vendor/**", "*.lock"})
+	for path, want := range map[string]bool{
+		"vendor/github.com/x/y.go": true,
+		"go.lock":                  true,
+		"sub/yarn.lock":            true,
+		"main.go":                  false,
+		"vendored.go":              false,
+	} {
+		if got := pathIgnored(ignore, path); got != want {
+			t.Errorf("pathIgnored(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	r := testrepo.New(t)
+	r.Commit("vendor@example.com", testrepo.DaysAgo(0), map[string]string{"vendor/lib/a.go": "a\n", "go.lock": "1\n"})
+	r.Commit("mixed@example.com", testrepo.DaysAgo(0), map[string]string{"vendor/lib/b.go": "b\n", "main.go": "main\n"})
+	opts := testOptions()
+	opts.IgnorePaths = []string{"vendor/**", "*.lock"}
+	_, owners := scan(t, opts, r.Dir)
+	if len(owners) != 1 || owners[0].Email != "mixed@example.com" || !near(owners[0].Score, 1) {
+		t.Errorf("got %+v, want only the mixed commit counted", owners)
+	}
+}
+
+func TestExtensions(t *testing.T) {
+	for ext, want := range map[string]string{".GO": "go", "*.tsx": "tsx", "proto": "proto", ".d.ts": "d.ts"} {
+		if got := NormalizeExtension(ext); got != want {
+			t.Errorf("NormalizeExtension(%q) = %q, want %q", ext, got, want)
+		}
+	}
+
+	r := testrepo.New(t)
+	r.Commit("backend@example.com", testrepo.DaysAgo(0), map[string]string{"server/main.go": "main\n"})
+	r.Commit("backend@example.com", testrepo.DaysAgo(0), map[string]string{"server/api.GO": "api\n"})
+	r.Commit("frontend@example.com", testrepo.DaysAgo(0), map[string]string{"web/App.tsx": "app\n"})
+	r.Commit("fullstack@example.com", testrepo.DaysAgo(0), map[string]string{"web/Form.tsx": "form\n", "server/form.go": "form\n", "README.md": "docs\n", "go.mod": "module\n"})
+
+	tests := []struct {
+		exts  []string
+		scale bool
+		want  map[string]float64
+	}{
+		{[]string{"go"}, false, map[string]float64{"backend@example.com": 2, "fullstack@example.com": 1}},
+		{[]string{"tsx"}, false, map[string]float64{"frontend@example.com": 1, "fullstack@example.com": 1}},
+		{[]string{"go"}, true, map[string]float64{"backend@example.com": 2, "fullstack@example.com": 0.25}},
+		{[]string{"go", "tsx"}, true, map[string]float64{"backend@example.com": 2, "frontend@example.com": 1, "fullstack@example.com": 0.5}},
+	}
+	for _, tt := range tests {
+		opts := testOptions()
+		opts.Extensions = tt.exts
+		opts.ScaleByExtension = tt.scale
+		_, owners := scan(t, opts, r.Dir)
+		got := scores(owners)
+		if len(got) != len(tt.want) {
+			t.Errorf("%v (scaled %v): got %v, want %v", tt.exts, tt.scale, got, tt.want)
+			continue
+		}
+		for email, want := range tt.want {
+			if !near(got[email], want) {
+				t.Errorf("%v (scaled %v): %s scored %g, want %g", tt.exts, tt.scale, email, got[email], want)
+			}
+		}
+	}
+}
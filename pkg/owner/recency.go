@@ -0,0 +1,20 @@
+package owner
+
+// ageBucket returns the AgeBuckets bucket of a commit daysAgo old: the first
+// bucket whose upper bound exceeds its age, or the open-ended last one.
+func (o ScanOptions) ageBucket(daysAgo float64) int {
+	for i, bound := range o.AgeBuckets {
+		if daysAgo < bound {
+			return i
+		}
+	}
+	return len(o.AgeBuckets)
+}
+
+// countAge counts one credited commit in a canonical user's age bucket.
+func (d *Data) countAge(canonicalEmail string, bucket, buckets int) {
+	if _, ok := d.AgeCounts[canonicalEmail]; !ok {
+		d.AgeCounts[canonicalEmail] = make([]int, buckets)
+	}
+	d.AgeCounts[canonicalEmail][bucket]++
+}
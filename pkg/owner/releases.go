@@ -0,0 +1,78 @@
+package owner
+
+import (
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// taggedCommits returns the commit of every tag in the repository.
+// Annotated tags are peeled to the commit they point at; tags pointing at
+// other objects are ignored.
+func taggedCommits(repo *git.Repository) ([]*object.Commit, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	var commits []*object.Commit
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		if tag, err := repo.TagObject(hash); err == nil {
+			commit, err := tag.Commit()
+			if err != nil {
+				return nil // Tag of a tree or blob
+			}
+			hash = commit.Hash
+		}
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return nil
+		}
+		commits = append(commits, commit)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// releaseTimes returns the sorted commit times of every tagged commit in the repository.
+func releaseTimes(repo *git.Repository) ([]time.Time, error) {
+	commits, err := taggedCommits(repo)
+	if err != nil {
+		return nil, err
+	}
+	times := make([]time.Time, 0, len(commits))
+	for _, commit := range commits {
+		times = append(times, commit.Committer.When)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times, nil
+}
+
+// releaseTips returns the hashes of every tagged commit, the starting points
+// of a walk over released history.
+func releaseTips(repo *git.Repository) ([]plumbing.Hash, error) {
+	commits, err := taggedCommits(repo)
+	if err != nil {
+		return nil, err
+	}
+	tips := make([]plumbing.Hash, 0, len(commits))
+	for _, commit := range commits {
+		tips = append(tips, commit.Hash)
+	}
+	return tips, nil
+}
+
+// nearRelease reports whether t lies within window of any of the sorted release times.
+func nearRelease(t time.Time, releases []time.Time, window time.Duration) bool {
+	i := sort.Search(len(releases), func(i int) bool { return !releases[i].Before(t) })
+	if i < len(releases) && releases[i].Sub(t) <= window {
+		return true // Next release at or after t
+	}
+	return i > 0 && t.Sub(releases[i-1]) <= window // Previous release before t
+}
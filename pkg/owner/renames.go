@@ -0,0 +1,58 @@
+package owner
+
+import "github.com/go-git/go-git/v5/plumbing/object"
+
+// renameLimit bounds rename detection like git's diff.renameLimit: commits
+// adding or deleting more files than this only get exact renames detected.
+const renameLimit = 1000
+
+// renameTracker follows files across renames while history is walked from
+// newest to oldest, like git log --follow: once the commit renaming a file is
+// seen, older commits touching the old path are credited to its latest name.
+type renameTracker struct {
+	opts   object.DiffTreeOptions
+	latest map[string]string // old path -> latest path
+}
+
+// newRenameTracker detects renames of files at least score percent similar.
+func newRenameTracker(score int) *renameTracker {
+	return &renameTracker{
+		opts:   object.DiffTreeOptions{DetectRenames: true, RenameScore: uint(score), RenameLimit: renameLimit},
+		latest: make(map[string]string),
+	}
+}
+
+// follow returns the paths a commit changed under their latest names and
+// records the renames it made for the older commits.
+func (t *renameTracker) follow(c *object.Commit) ([]string, error) {
+	changes, err := commitChanges(c, &t.opts)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(changes))
+	renamed := make(map[string]string)
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" { // Deletion
+			name = change.From.Name
+		}
+		name = t.current(name)
+		paths = append(paths, name)
+		if change.From.Name != "" && change.To.Name != "" && change.From.Name != change.To.Name {
+			renamed[change.From.Name] = name
+		}
+	}
+	// Recorded last, so a commit swapping two names does not chain them
+	for from, to := range renamed {
+		t.latest[from] = to
+	}
+	return paths, nil
+}
+
+// current returns the latest name of a path seen so far.
+func (t *renameTracker) current(path string) string {
+	if latest, ok := t.latest[path]; ok {
+		return latest
+	}
+	return path
+}
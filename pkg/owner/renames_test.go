@@ -0,0 +1,58 @@
+package owner
+
+import (
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestRenamesFollowed(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(30), map[string]string{"old/parser.go": testrepo.Lines(40)})
+	r.Commit("dave@example.com", testrepo.DaysAgo(25), map[string]string{"old/lexer.go": testrepo.Lines(40)})
+	r.Commit("bob@example.com", testrepo.DaysAgo(20), map[string]string{"old/parser.go": "", "new/parser.go": testrepo.Lines(41)})
+	// Deleted and rewritten from scratch: too different to be a rename
+	r.Commit("erin@example.com", testrepo.DaysAgo(15), map[string]string{"old/lexer.go": "", "new/lexer.go": "package lexer\n"})
+	r.Commit("carol@example.com", testrepo.DaysAgo(10), map[string]string{"new/parser.go": testrepo.Lines(45)})
+
+	tests := []struct {
+		renameScore int
+		want        []string
+	}{
+		{0, []string{"bob@example.com", "carol@example.com", "erin@example.com"}},
+		{50, []string{"alice@example.com", "bob@example.com", "carol@example.com", "erin@example.com"}},
+		{100, []string{"bob@example.com", "carol@example.com", "erin@example.com"}}, // The rename changed a line
+	}
+	for _, tt := range tests {
+		opts := testOptions()
+		opts.PathPrefixes = []string{"new/"}
+		opts.RenameScore = tt.renameScore
+		_, owners := scan(t, opts, r.Dir)
+		got := scores(owners)
+		if len(got) != len(tt.want) {
+			t.Errorf("rename score %d: got %v, want %v", tt.renameScore, got, tt.want)
+			continue
+		}
+		for _, email := range tt.want {
+			if _, ok := got[email]; !ok {
+				t.Errorf("rename score %d: %s missing from %v", tt.renameScore, email, got)
+			}
+		}
+	}
+
+	// Per-file ownership credits the old commits to the new path only
+	opts := testOptions()
+	opts.RenameScore = 50
+	opts.TrackFiles = true
+	data, _ := scan(t, opts, r.Dir)
+	files := make(map[string]map[string]float64)
+	for key, weights := range data.Files {
+		files[key.Path] = weights
+	}
+	if _, ok := files["old/parser.go"]; ok {
+		t.Errorf("old path still owned: %v", files["old/parser.go"])
+	}
+	if _, ok := files["new/parser.go"]["alice@example.com"]; !ok {
+		t.Errorf("alice does not own new/parser.go: %v", files["new/parser.go"])
+	}
+}
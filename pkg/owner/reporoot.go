@@ -0,0 +1,78 @@
+package owner
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// openRepository opens the repository containing path, walking up from a
+// subdirectory of a working tree to its top level like git does. The walk
+// only looks for .git entries, so path itself is tried first for bare
+// repositories.
+func openRepository(path string) (*git.Repository, error) {
+	repo, err := git.PlainOpen(path)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	}
+	return repo, err
+}
+
+// RepoRoot returns the top level of the repository containing path, found
+// by walking up to the nearest .git entry and expressed relative to path
+// (src/.. for src). A repository's own top level and bare repositories are
+// returned unchanged. ok is false when path is in no repository.
+func RepoRoot(path string) (root string, ok bool) {
+	if _, err := git.PlainOpen(path); err == nil {
+		return path, true
+	}
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", false
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(abs, wt.Filesystem.Root())
+	if err != nil {
+		return wt.Filesystem.Root(), true
+	}
+	return filepath.Join(path, rel), true
+}
+
+// topLevel returns the top level of the repository containing path, noting
+// when it differs from path. Paths outside any repository are returned as
+// given, for the scan to report.
+func topLevel(path string) string {
+	root, ok := RepoRoot(path)
+	if !ok || filepath.Clean(root) == filepath.Clean(path) {
+		return path
+	}
+	Infof("Note: %s is inside the repository at %s; analyzing the whole repository.", path, root)
+	return root
+}
+
+// ResolveRepoRoots replaces every path below the top level of a working tree
+// by that top level, as ScanRepos does, and keeps a repository reached twice
+// once, so a caller can key per-repository settings the way the scan keys
+// its data.
+func ResolveRepoRoots(paths []string) []string {
+	seen := make(map[string]struct{}, len(paths))
+	var resolved []string
+	for _, path := range paths {
+		path = topLevel(path)
+		key := filepath.Clean(path)
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		resolved = append(resolved, path)
+	}
+	return resolved
+}
@@ -0,0 +1,50 @@
+package owner
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestScanFromSubdirectory(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(1), map[string]string{"src/pkg/a.go": "a\n"})
+	r.Commit("bob@example.com", testrepo.DaysAgo(2), map[string]string{"docs/b.md": "b\n"})
+	sub := filepath.Join(r.Dir, "src", "pkg")
+
+	if root, ok := RepoRoot(sub); !ok || filepath.Clean(root) != r.Dir {
+		t.Errorf("RepoRoot(%s) = %s, %v; want %s", sub, root, ok, r.Dir)
+	}
+	if root, ok := RepoRoot(r.Dir); !ok || root != r.Dir {
+		t.Errorf("RepoRoot of the root = %s, %v", root, ok)
+	}
+
+	// The subdirectory and the top level are one repository, scored as a whole
+	for _, fullBlame := range []bool{false, true} {
+		opts := testOptions()
+		opts.FullBlame = fullBlame
+		opts.BlameWorkers = 1
+		_, owners := scan(t, opts, sub, r.Dir)
+		if len(owners) != 2 || owners[0].RepoCount != 1 || owners[1].RepoCount != 1 {
+			t.Errorf("full blame %v: got %+v, want alice and bob from 1 repository", fullBlame, owners)
+		}
+	}
+
+	// Library callers reach the blame workers without ResolveRepoRoots
+	_, head, err := OpenRepo(sub, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blamed, err := blameFiles(context.Background(), sub, head, []string{"docs/b.md"}, 1, false)
+	if err != nil || len(blamed["docs/b.md"]) != 1 {
+		t.Errorf("blaming from %s: got %v, %v", sub, blamed, err)
+	}
+
+	opts := testOptions()
+	opts.NoDetectDotGit = true
+	if _, failed := ScanRepos(context.Background(), []string{sub}, opts); len(failed) != 1 {
+		t.Errorf("NoDetectDotGit: got failures %v, want %s", failed, sub)
+	}
+}
@@ -0,0 +1,110 @@
+package owner
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var (
+	// revertHashRe matches the trailer "git revert" writes into the message body.
+	revertHashRe = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-f]{7,40})`)
+	// revertSubjectRe matches the default subject "git revert" generates.
+	revertSubjectRe = regexp.MustCompile(`^Revert "(.+)"$`)
+)
+
+// revertInfo is what the revert analysis keeps about each commit.
+type revertInfo struct {
+	hash    plumbing.Hash
+	when    time.Time // Committer time, for ordering
+	subject string
+	target  string // Referenced hash (possibly abbreviated) or quoted subject of the reverted commit
+	byHash  bool   // Whether target is a hash rather than a subject
+}
+
+// discountedByReverts walks the history reachable from tips (the same ones as
+// the scoring walk) and returns the
+// commits whose weight should be discounted under --discount-reverts:
+//
+//   - every revert commit, since a revert only undoes work, and
+//   - every commit whose net effect was removed, i.e. it was reverted by a
+//     revert that was not itself reverted. A commit that was reverted and then
+//     reinstated (the revert was reverted) keeps its full weight.
+//
+// Reverts are recognized only by the conventional messages "git revert"
+// writes ("Revert \"<subject>\"" and "This reverts commit <hash>."), so
+// hand-edited or squashed reverts are missed, and subject matching can pick
+// the wrong commit when several commits share a subject.
+func discountedByReverts(repo *git.Repository, tips []plumbing.Hash) (map[plumbing.Hash]struct{}, error) {
+	iter, err := walkFrom(repo, tips)
+	if err != nil {
+		return nil, err
+	}
+	var commits []revertInfo
+	err = iter.ForEach(func(c *object.Commit) error {
+		subject, _, _ := strings.Cut(c.Message, "\n")
+		info := revertInfo{hash: c.Hash, when: c.Committer.When, subject: strings.TrimSpace(subject)}
+		if m := revertHashRe.FindStringSubmatch(c.Message); m != nil {
+			info.target, info.byHash = m[1], true
+		} else if m := revertSubjectRe.FindStringSubmatch(info.subject); m != nil {
+			info.target = m[1]
+		}
+		commits = append(commits, info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Newest first: a walk over several tips can reach a branch's commits
+	// after history older than them
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].when.After(commits[j].when) })
+
+	// revertedBy maps a commit to the commits that revert it
+	revertedBy := make(map[plumbing.Hash][]plumbing.Hash)
+	isRevert := make(map[plumbing.Hash]bool)
+	for i, info := range commits {
+		if info.target == "" {
+			continue
+		}
+		// The reverted commit is older, so only look further down the log
+		for _, candidate := range commits[i+1:] {
+			if (info.byHash && strings.HasPrefix(candidate.hash.String(), info.target)) ||
+				(!info.byHash && candidate.subject == info.target) {
+				revertedBy[candidate.hash] = append(revertedBy[candidate.hash], info.hash)
+				isRevert[info.hash] = true
+				break
+			}
+		}
+	}
+
+	// A commit's effect is removed if any of its reverts is itself still in effect
+	memo := make(map[plumbing.Hash]bool)
+	var removed func(h plumbing.Hash) bool
+	removed = func(h plumbing.Hash) bool {
+		if v, ok := memo[h]; ok {
+			return v
+		}
+		result := false
+		for _, r := range revertedBy[h] {
+			if !removed(r) {
+				result = true
+				break
+			}
+		}
+		memo[h] = result
+		return result
+	}
+
+	discounted := make(map[plumbing.Hash]struct{})
+	for _, info := range commits {
+		if isRevert[info.hash] || removed(info.hash) {
+			discounted[info.hash] = struct{}{}
+		}
+	}
+	return discounted, nil
+}
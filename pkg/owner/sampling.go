@@ -0,0 +1,49 @@
+package owner
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// sampled reports whether a commit falls into the sample. The decision is
+// derived from the commit hash mixed with the run's seed, so with the same
+// seed a commit is always in or out of the sample regardless of which
+// repository or run it is seen in.
+func sampled(hash plumbing.Hash, rate float64, seed uint64) bool {
+	v := splitmix64(binary.BigEndian.Uint64(hash[:8]) ^ seed)
+	return float64(v) < rate*math.MaxUint64
+}
+
+// splitmix64 is a fast, well-distributed 64-bit mixing function.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// scoreMargin returns the half-width of an approximate 95% interval for a
+// sampled score, given its estimated variance and the number of sampled
+// commits behind it. Student's t replaces the normal quantile so authors with
+// only a handful of sampled commits get visibly wider intervals.
+func scoreMargin(variance float64, n int) float64 {
+	if variance <= 0 {
+		return 0
+	}
+	return tCritical95(n-1) * math.Sqrt(variance)
+}
+
+// tCritical95 approximates the two-sided 95% quantile of Student's t
+// distribution with df degrees of freedom (Cornish-Fisher expansion).
+func tCritical95(df int) float64 {
+	const z = 1.959964
+	if df < 1 {
+		return 12.706 // df=1 value; a single observation gets the widest interval
+	}
+	d := float64(df)
+	z3 := z * z * z
+	z5 := z3 * z * z
+	return z + (z3+z)/(4*d) + (5*z5+16*z3+3*z)/(96*d*d)
+}
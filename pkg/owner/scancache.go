@@ -0,0 +1,262 @@
+package owner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// scanCacheVersion is bumped whenever the scan cache layout or the scoring
+// changes; caches with another version are ignored.
+const scanCacheVersion = 2
+
+// scanCacheEntry is the accumulated commit-walk result of one repository at
+// one state, as of Now. Repository paths are left out, so the entry can be
+// restored under whatever path the repository is given as.
+type scanCacheEntry struct {
+	Version      int                           `json:"version"`
+	State        string                        `json:"state"` // HEAD hash, or a digest of every ref when more than HEAD is walked
+	Now          time.Time                     `json:"now"`
+	Scores       map[string]float64            `json:"scores"`
+	Contributors []string                      `json:"contributors"`
+	Aliases      map[string][]string           `json:"aliases"`
+	Names        map[string]map[string]int     `json:"names"`
+	Commits      map[string]int                `json:"commits"`
+	Days         map[string][]string           `json:"days"`
+	Vars         map[string]float64            `json:"vars"`
+	Files        map[string]map[string]float64 `json:"files"` // path -> canonical_email -> weight
+	Tickets      map[string]int                `json:"tickets"`
+	Scored       int                           `json:"scored"`
+	Invalid      map[string]int                `json:"invalid"`
+	LastActive   map[string]time.Time          `json:"last_active"`
+}
+
+// scanCacheable reports whether a repository's walk can be cached on its own.
+// Cross-repository deduplication and active days depend on the other
+// repositories, the per-commit log is not worth storing, and the undecayed
+// origin bonus would be rescaled with the decayed weights. OnCommit needs
+// every commit walked, and age buckets shift as time passes.
+func (o ScanOptions) scanCacheable() bool {
+	return o.CacheDir != "" && !o.DedupAcrossRepos && o.WeightBy != WeightByActiveDays && !o.RecordCommits && o.OriginBonus == 0 && o.OnCommit == nil && len(o.AgeBuckets) == 0
+}
+
+// scanCacheFile returns the cache file of a repository under the options that
+// affect scoring. Options that only change how the scan runs are left out of
+// the key, and so is Now: entries are rescaled to the current time on load.
+func scanCacheFile(repoPath string, opts ScanOptions) (string, error) {
+	abs, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", err
+	}
+	keyed := opts
+	keyed.Now, keyed.Progress, keyed.Strict = time.Time{}, false, false
+	keyed.BlameWorkers, keyed.BlameCache, keyed.CacheDir = 0, false, ""
+	keyed.RepoTau = nil // Already applied to Tau
+	keyed.RepoWeight = nil
+	if keyed.SampleRate >= 1 {
+		keyed.Seed = 0 // Drawn at random on every run, but unused without sampling
+	}
+	raw, err := json.Marshal(struct {
+		Version int
+		Path    string
+		Weight  float64 // This repository's RepoWeight, so other repositories' weights do not matter
+		Options ScanOptions
+	}{scanCacheVersion, abs, opts.repoWeight(repoPath), keyed})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return filepath.Join(opts.CacheDir, hex.EncodeToString(sum[:16])+".json"), nil
+}
+
+// scanState identifies what a walk of the repository would visit: the HEAD
+// (or --ref) commit, plus every ref when branches or tags are walked or
+// scored too.
+func scanState(repo *git.Repository, head plumbing.Hash, opts ScanOptions) (string, error) {
+	if !opts.AllBranches && !opts.ReleasedOnly && opts.ReleaseBonus == 0 {
+		return head.String(), nil
+	}
+	refs, err := repo.References()
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference {
+			lines = append(lines, ref.Name().String()+" "+ref.Hash().String())
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(lines)
+	h := sha256.New()
+	fmt.Fprintln(h, head)
+	for _, line := range lines {
+		fmt.Fprintln(h, line)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cacheRescale returns the factor turning weights decayed as of from into
+// weights decayed as of o.Now, if the decay function allows it: exponential
+// decay shifts every weight by the same factor, no decay by none.
+func (o ScanOptions) cacheRescale(from time.Time) (float64, bool) {
+	switch {
+	case o.Now.Equal(from) || o.Decay == DecayNone:
+		return 1, true
+	case (o.Decay == "" || o.Decay == DecayExponential) && o.WeightFloor == 0:
+		return math.Exp(-o.Now.Sub(from).Hours() / 24 / o.Tau), true
+	}
+	return 0, false
+}
+
+// loadScanCache returns the cached walk of a repository if it was stored for
+// state and can be rescaled to opts.Now. A missing, unreadable or outdated
+// entry is a miss, so the cache never blocks a run.
+func loadScanCache(file, repoPath, state string, opts ScanOptions) (*Data, bool) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	var entry scanCacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		Warnf("Warning: ignoring unreadable scan cache %s", file)
+		return nil, false
+	}
+	if entry.Version != scanCacheVersion || entry.State != state {
+		return nil, false
+	}
+	k, ok := opts.cacheRescale(entry.Now)
+	if !ok {
+		return nil, false
+	}
+	// Ages are clamped at zero, so commits after either reference time do not rescale
+	for _, t := range entry.LastActive {
+		if t.After(entry.Now) || t.After(opts.Now) {
+			return nil, false
+		}
+	}
+
+	data := NewData()
+	for email, score := range entry.Scores {
+		data.Scores[email] = score * k
+	}
+	for _, email := range entry.Contributors {
+		data.addRepo(email, repoPath)
+	}
+	for email, aliases := range entry.Aliases {
+		data.aliases[email] = make(map[string]struct{}, len(aliases))
+		for _, alias := range aliases {
+			data.aliases[email][alias] = struct{}{}
+		}
+	}
+	for email, score := range entry.Scores {
+		data.RepoScores[email] = map[string]float64{repoPath: score * k}
+	}
+	for email, v := range entry.Vars {
+		data.vars[email] = v * k * k // Variances scale with the square of the weights
+	}
+	for path, weights := range entry.Files {
+		key := FileKey{Repo: repoPath, Path: path}
+		data.Files[key] = make(map[string]float64, len(weights))
+		for email, w := range weights {
+			data.Files[key][email] = w * k
+		}
+	}
+	data.names = nonNil(entry.Names)
+	data.commits = nonNil(entry.Commits)
+	for email, days := range entry.Days {
+		data.days[email] = make(map[string]struct{}, len(days))
+		for _, day := range days {
+			data.days[email][day] = struct{}{}
+		}
+	}
+	data.tickets = nonNil(entry.Tickets)
+	data.Invalid = nonNil(entry.Invalid)
+	data.LastActive = nonNil(entry.LastActive)
+	data.Scored = entry.Scored
+	return data, true
+}
+
+// nonNil returns m, or an empty map if it is nil.
+func nonNil[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return make(map[K]V)
+	}
+	return m
+}
+
+// saveScanCache writes one repository's walk atomically (write to a temporary
+// file, then rename).
+func saveScanCache(file, repoPath, state string, now time.Time, data *Data) error {
+	entry := scanCacheEntry{
+		Version:    scanCacheVersion,
+		State:      state,
+		Now:        now,
+		Scores:     data.Scores,
+		Aliases:    make(map[string][]string, len(data.aliases)),
+		Names:      data.names,
+		Commits:    data.commits,
+		Days:       make(map[string][]string, len(data.days)),
+		Vars:       data.vars,
+		Files:      make(map[string]map[string]float64),
+		Tickets:    data.tickets,
+		Scored:     data.Scored,
+		Invalid:    data.Invalid,
+		LastActive: data.LastActive,
+	}
+	for email := range data.repos {
+		entry.Contributors = append(entry.Contributors, email)
+	}
+	sort.Strings(entry.Contributors)
+	for email, aliases := range data.aliases {
+		for alias := range aliases {
+			entry.Aliases[email] = append(entry.Aliases[email], alias)
+		}
+		sort.Strings(entry.Aliases[email])
+	}
+	for email, days := range data.days {
+		for day := range days {
+			entry.Days[email] = append(entry.Days[email], day)
+		}
+		sort.Strings(entry.Days[email])
+	}
+	for key, weights := range data.Files {
+		if key.Repo == repoPath {
+			entry.Files[key.Path] = weights
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".scan-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
@@ -0,0 +1,106 @@
+package owner
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestScanCacheHitMatchesFreshScan(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(200), nil)
+	r.Commit("bob@example.com", testrepo.DaysAgo(30), nil)
+	r.Commit("alice@example.com", testrepo.DaysAgo(2), nil)
+
+	_, fresh := scan(t, testOptions(), r.Dir)
+	opts := testOptions()
+	opts.CacheDir = t.TempDir()
+	_, first := scan(t, opts, r.Dir)
+	file, err := scanCacheFile(r.Dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("no cache entry written: %v", err)
+	}
+	_, cached := scan(t, opts, r.Dir)
+
+	for _, owners := range [][]OwnerScore{first, cached} {
+		if !reflect.DeepEqual(owners, fresh) {
+			t.Errorf("got %+v, want %+v", owners, fresh)
+		}
+	}
+}
+
+func TestScanCacheInvalidation(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(10), nil)
+	opts := testOptions()
+	opts.CacheDir = t.TempDir()
+	scan(t, opts, r.Dir)
+
+	// A new commit moves HEAD, so the cached walk no longer applies
+	r.Commit("bob@example.com", testrepo.DaysAgo(1), nil)
+	_, owners := scan(t, opts, r.Dir)
+	if got := scores(owners); len(got) != 2 || got["bob@example.com"] == 0 {
+		t.Errorf("after a new commit got %v, want alice and bob", got)
+	}
+
+	// Scoring options are part of the key
+	tau := opts
+	tau.Tau = 30
+	a, err := scanCacheFile(r.Dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := scanCacheFile(r.Dir, tau)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Error("changing --tau kept the same cache file")
+	}
+
+	// Entries of another format version are ignored
+	raw, err := os.ReadFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := fmt.Sprintf(`"version":%d`, scanCacheVersion)
+	stale := strings.Replace(string(raw), version, fmt.Sprintf(`"version":%d`, scanCacheVersion+1), 1)
+	if stale == string(raw) {
+		t.Fatalf("no version in cache entry %s", raw)
+	}
+	if err := os.WriteFile(a, []byte(stale), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repo, head, err := OpenRepo(r.Dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := scanState(repo, head, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadScanCache(a, r.Dir, state, opts); ok {
+		t.Error("loaded a cache entry of another version")
+	}
+
+	// A later run rescales the cached weights to its own time
+	later := opts
+	later.Now = opts.Now.AddDate(0, 0, 30)
+	scan(t, later, r.Dir) // Rewrites the stale entry
+	later.Now = later.Now.AddDate(0, 0, 30)
+	_, cached := scan(t, later, r.Dir)
+	later.CacheDir = ""
+	_, fresh := scan(t, later, r.Dir)
+	for email, want := range scores(fresh) {
+		if got := scores(cached)[email]; !near(got, want) {
+			t.Errorf("rescaled cache: %s scored %g, want %g", email, got, want)
+		}
+	}
+}
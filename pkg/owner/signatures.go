@@ -0,0 +1,37 @@
+package owner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// LoadKeyring reads an ASCII-armored OpenPGP public keyring used to verify commit signatures.
+func LoadKeyring(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read keyring %s: %w", filePath, err)
+	}
+	return string(data), nil
+}
+
+// signatureVerified reports whether a commit carries a PGP signature that
+// verifies against the armored keyring. go-git parses the keyring and checks
+// the signature for every call, so this adds noticeable cost per signed commit.
+func signatureVerified(c *object.Commit, keyring string) bool {
+	if c.PGPSignature == "" {
+		return false
+	}
+	_, err := c.Verify(keyring)
+	return err == nil
+}
+
+// signatureTrusted reports whether a commit passes SignedOnly: it carries a
+// PGP signature, which must verify against Keyring with VerifySignatures.
+func (o ScanOptions) signatureTrusted(c *object.Commit) bool {
+	if o.VerifySignatures {
+		return signatureVerified(c, o.Keyring)
+	}
+	return c.PGPSignature != ""
+}
@@ -0,0 +1,42 @@
+package owner
+
+import (
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+// stubSignature looks like a PGP signature but verifies against no key.
+const stubSignature = "-----BEGIN PGP SIGNATURE-----\n\niQEzBAABCAAdFiEEstub\n-----END PGP SIGNATURE-----\n"
+
+func TestSignedOnly(t *testing.T) {
+	r := testrepo.New(t)
+	r.CommitSigned("signed@example.com", testrepo.DaysAgo(0), stubSignature, nil)
+	r.CommitSigned("signed@example.com", testrepo.DaysAgo(0), stubSignature, nil)
+	r.Commit("signed@example.com", testrepo.DaysAgo(0), nil)
+	r.Commit("unsigned@example.com", testrepo.DaysAgo(0), nil)
+
+	tests := []struct {
+		signedOnly, verify bool
+		want               map[string]float64
+	}{
+		{false, false, map[string]float64{"signed@example.com": 3, "unsigned@example.com": 1}},
+		{true, false, map[string]float64{"signed@example.com": 2}},
+		{true, true, map[string]float64{}}, // The stub signatures do not verify
+	}
+	for _, tt := range tests {
+		opts := testOptions()
+		opts.SignedOnly, opts.VerifySignatures = tt.signedOnly, tt.verify
+		_, owners := scan(t, opts, r.Dir)
+		got := scores(owners)
+		if len(got) != len(tt.want) {
+			t.Errorf("signed only %v, verify %v: got %v, want %v", tt.signedOnly, tt.verify, got, tt.want)
+			continue
+		}
+		for email, want := range tt.want {
+			if !near(got[email], want) {
+				t.Errorf("signed only %v, verify %v: %s scored %g, want %g", tt.signedOnly, tt.verify, email, got[email], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package owner
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Primary sort keys of a ranking (--sort). Ties always fall back to score,
+// then repository count, then email, so the order is deterministic.
+const (
+	SortScore  = "score"  // Highest final score first (default)
+	SortRepos  = "repos"  // Most repositories first
+	SortRecent = "recent" // Most recent counted commit first
+	SortEmail  = "email"  // Alphabetical by canonical email
+)
+
+// ValidSort reports an error unless key names a sort key ("" means score).
+func ValidSort(key string) error {
+	switch key {
+	case "", SortScore, SortRepos, SortRecent, SortEmail:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown sort key %q (expected score, repos, recent, or email)", ErrParse, key)
+}
+
+// SortOwners orders owners by the primary key, breaking ties by score
+// (descending), repository count (descending) and email.
+func SortOwners(owners []OwnerScore, key string) {
+	sort.Slice(owners, func(i, j int) bool {
+		a, b := owners[i], owners[j]
+		switch key {
+		case SortRepos:
+			if a.RepoCount != b.RepoCount {
+				return a.RepoCount > b.RepoCount
+			}
+		case SortRecent:
+			if !a.LastActive.Equal(b.LastActive) {
+				return a.LastActive.After(b.LastActive)
+			}
+		case SortEmail:
+			return a.Email < b.Email
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.RepoCount != b.RepoCount {
+			return a.RepoCount > b.RepoCount
+		}
+		return a.Email < b.Email
+	})
+}
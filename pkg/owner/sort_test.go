@@ -0,0 +1,47 @@
+package owner
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSortOwners(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	owners := []OwnerScore{
+		{Email: "dora@example.com", Score: 1, RepoCount: 1, LastActive: day(20)},
+		{Email: "bob@example.com", Score: 2, RepoCount: 1, LastActive: day(10)},
+		{Email: "carol@example.com", Score: 2, RepoCount: 3, LastActive: day(5)},
+		{Email: "alice@example.com", Score: 2, RepoCount: 1, LastActive: day(10)},
+		{Email: "erin@example.com", Score: 0.5, RepoCount: 3, LastActive: day(20)},
+	}
+	tests := map[string][]string{
+		SortScore:  {"carol", "alice", "bob", "dora", "erin"}, // Ties on score go to repositories, then email
+		"":         {"carol", "alice", "bob", "dora", "erin"},
+		SortRepos:  {"carol", "erin", "alice", "bob", "dora"},
+		SortRecent: {"dora", "erin", "alice", "bob", "carol"},
+		SortEmail:  {"alice", "bob", "carol", "dora", "erin"},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for key, want := range tests {
+		// Every input order gives the same ranking
+		for run := 0; run < 5; run++ {
+			shuffled := append([]OwnerScore(nil), owners...)
+			rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+			SortOwners(shuffled, key)
+			var got []string
+			for _, o := range shuffled {
+				got = append(got, strings.TrimSuffix(o.Email, "@example.com"))
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("--sort=%q: got %v, want %v", key, got, want)
+				break
+			}
+		}
+	}
+	if err := ValidSort("age"); err == nil {
+		t.Error("expected an error for an unknown sort key")
+	}
+}
@@ -0,0 +1,17 @@
+package owner
+
+import (
+	"regexp"
+)
+
+// DefaultTicketRegex matches GitHub-style (#123) and Jira-style (PROJ-456) references.
+const DefaultTicketRegex = `#[0-9]+\b|\b[A-Z][A-Z0-9]+-[0-9]+\b`
+
+// countTickets returns the number of distinct ticket references in a commit message.
+func countTickets(message string, pattern *regexp.Regexp) int {
+	seen := make(map[string]struct{})
+	for _, ref := range pattern.FindAllString(message, -1) {
+		seen[ref] = struct{}{}
+	}
+	return len(seen)
+}
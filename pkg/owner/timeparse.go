@@ -0,0 +1,78 @@
+package owner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Every date and duration flag goes through parseWhen and ParseDuration, so
+// they all accept the same syntax:
+//
+//	durations: 90d, 2w, 6mo, 1y (a month is 30 days, a year 365), or Go durations like 36h
+//	times:     RFC3339, YYYY-MM-DD (UTC midnight), now, today, yesterday,
+//	           or a duration meaning that long ago (90d, "6mo ago")
+
+// durationUnits are the calendar suffixes accepted by ParseDuration, in days.
+// "mo" comes before "d" and "y" so the longer suffix is tried first.
+var durationUnits = []struct {
+	suffix string
+	days   float64
+}{{"mo", 30}, {"d", 1}, {"w", 7}, {"y", 365}}
+
+// ParseDuration parses a non-negative span of time such as "90d", "2w",
+// "6mo" or "1y". Plain Go durations like "36h" are accepted as well.
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for _, u := range durationUnits {
+		if num, ok := strings.CutSuffix(value, u.suffix); ok {
+			n, err := strconv.ParseFloat(num, 64)
+			if err != nil || n < 0 {
+				break
+			}
+			return time.Duration(n * u.days * 24 * float64(time.Hour)), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("%w: invalid duration %q (expected e.g. 90d, 2w, 6mo, 1y)", ErrParse, value)
+}
+
+// parseWhen parses a point in time. Relative inputs (keywords and durations)
+// are resolved against now, so a whole run agrees on what "90d" means.
+func parseWhen(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	today := now.UTC().Truncate(24 * time.Hour)
+	switch strings.ToLower(value) {
+	case "now":
+		return now, nil
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	if d, err := ParseDuration(strings.TrimSuffix(value, " ago")); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%w: invalid time %q (expected RFC3339, YYYY-MM-DD, now, today, yesterday, or an age like 90d)", ErrParse, value)
+}
+
+// namesWholeDay reports whether a parseWhen input denotes a calendar day
+// rather than an instant, so an end bound can include the entire day.
+func namesWholeDay(value string) bool {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "today", "yesterday":
+		return true
+	}
+	_, err := time.Parse(time.DateOnly, value)
+	return err == nil
+}
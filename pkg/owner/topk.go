@@ -0,0 +1,61 @@
+package owner
+
+import (
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// TopKCandidates is the cheap first pass of --top-k-precise: it counts the
+// commits credited to each canonical identity across all repositories (no
+// decay, no diffs) and returns the k identities with the most commits. The
+// detailed scoring pass then only accumulates data for these identities.
+//
+// Because candidates are picked by raw commit count rather than by decayed
+// score, an author with few but very recent commits can miss the cut while
+// narrowly outscoring someone who made it; only borderline authors are
+// affected. Repositories that fail to open are skipped here and reported by
+// the scoring pass.
+func TopKCandidates(repoPaths []string, opts ScanOptions, k int) map[string]struct{} {
+	counts := make(map[string]int)
+	screen := NewData() // Applies the invalid-email policy; its counts are discarded
+	for _, repoPath := range repoPaths {
+		repo, head, err := OpenRepo(repoPath, opts.Ref)
+		if err != nil {
+			continue
+		}
+		iter, err := repo.Log(&git.LogOptions{From: head})
+		if err != nil {
+			continue
+		}
+		_ = iter.ForEach(func(c *object.Commit) error {
+			for _, cr := range commitCredits(c, opts) {
+				if email, ok := screen.screenEmail(cr.Sig.Email, cr.CanonicalEmail, opts.InvalidEmails); ok {
+					counts[email]++
+				}
+			}
+			return nil
+		})
+	}
+
+	emails := make([]string, 0, len(counts))
+	for email := range counts {
+		emails = append(emails, email)
+	}
+	sort.Slice(emails, func(i, j int) bool {
+		if counts[emails[i]] != counts[emails[j]] {
+			return counts[emails[i]] > counts[emails[j]]
+		}
+		return emails[i] < emails[j]
+	})
+	if len(emails) > k {
+		emails = emails[:k]
+	}
+
+	candidates := make(map[string]struct{}, len(emails))
+	for _, email := range emails {
+		candidates[email] = struct{}{}
+	}
+	return candidates
+}
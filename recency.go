@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// recencyBucket is one column of the recency histogram.
+type recencyBucket struct {
+	Label   string `json:"label"`
+	Commits int    `json:"commits"`
+}
+
+// recencyRow is one owner's commits bucketed by age.
+type recencyRow struct {
+	Email   string          `json:"email"`
+	Buckets []recencyBucket `json:"buckets"`
+}
+
+// parseAgeBuckets parses --recency-buckets: comma-separated ages such as
+// 30d,90d,365d, in increasing order. It returns the bounds in days.
+func parseAgeBuckets(value string) ([]float64, error) {
+	var bounds []float64
+	for _, field := range strings.Split(value, ",") {
+		d, err := owner.ParseDuration(field)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%q is not a positive age like 30d", strings.TrimSpace(field))
+		}
+		days := d.Hours() / 24
+		if len(bounds) > 0 && days <= bounds[len(bounds)-1] {
+			return nil, fmt.Errorf("ages must be increasing, got %s after %s", strings.TrimSpace(field), formatDays(bounds[len(bounds)-1]))
+		}
+		bounds = append(bounds, days)
+	}
+	return bounds, nil
+}
+
+// formatDays renders a bucket bound, e.g. 30d.
+func formatDays(days float64) string {
+	return strconv.FormatFloat(days, 'f', -1, 64) + "d"
+}
+
+// recencyLabels names the buckets delimited by bounds: 0-30d, 30-90d, ...,
+// >365d.
+func recencyLabels(bounds []float64) []string {
+	labels := make([]string, 0, len(bounds)+1)
+	lower := "0"
+	for _, bound := range bounds {
+		labels = append(labels, lower+"-"+formatDays(bound))
+		lower = strconv.FormatFloat(bound, 'f', -1, 64)
+	}
+	return append(labels, ">"+formatDays(bounds[len(bounds)-1]))
+}
+
+// recencyHistogram returns the age buckets of every owner, in ranking order.
+func recencyHistogram(owners []owner.OwnerScore, counts map[string][]int, bounds []float64) []recencyRow {
+	labels := recencyLabels(bounds)
+	rows := make([]recencyRow, len(owners))
+	for i, o := range owners {
+		rows[i] = recencyRow{Email: o.Email, Buckets: make([]recencyBucket, len(labels))}
+		for j, label := range labels {
+			rows[i].Buckets[j] = recencyBucket{Label: label}
+			if j < len(counts[o.Email]) {
+				rows[i].Buckets[j].Commits = counts[o.Email][j]
+			}
+		}
+	}
+	return rows
+}
+
+// printRecencyText prints the histogram as an aligned table.
+func printRecencyText(rows []recencyRow, bounds []float64) {
+	fmt.Println("\n--- Commit Recency ---")
+	if len(rows) == 0 {
+		fmt.Println("No owners found.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Rank\tEmail\t%s\n", strings.Join(recencyLabels(bounds), "\t"))
+	for i, row := range rows {
+		fmt.Fprintf(w, "%d\t%s", i+1, row.Email)
+		for _, b := range row.Buckets {
+			fmt.Fprintf(w, "\t%d", b.Commits)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
+
+// printRecencyMarkdown prints the histogram as a Markdown table.
+func printRecencyMarkdown(rows []recencyRow, bounds []float64) {
+	labels := recencyLabels(bounds)
+	fmt.Printf("| Rank | Email | %s |\n", strings.Join(labels, " | "))
+	fmt.Printf("|---:|---|%s\n", strings.Repeat("---:|", len(labels)))
+	for i, row := range rows {
+		fmt.Printf("| %d | %s |", i+1, escapeMarkdownCell(row.Email))
+		for _, b := range row.Buckets {
+			fmt.Printf(" %d |", b.Commits)
+		}
+		fmt.Println()
+	}
+}
+
+// printRecencyJSON prints the histogram as a JSON array.
+func printRecencyJSON(rows []recencyRow) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rows)
+}
+
+// printRecencyCSV prints the histogram as CSV with one column per bucket.
+func printRecencyCSV(rows []recencyRow, bounds []float64) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write(append([]string{"rank", "email"}, recencyLabels(bounds)...))
+	for i, row := range rows {
+		record := []string{strconv.Itoa(i + 1), row.Email}
+		for _, b := range row.Buckets {
+			record = append(record, strconv.Itoa(b.Commits))
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return w.Error()
+}
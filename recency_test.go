@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestRecencyHistogram(t *testing.T) {
+	r := testrepo.New(t)
+	for _, days := range []float64{1, 29, 30, 89, 200, 364, 365, 1000} {
+		r.Commit("alice@example.com", testrepo.DaysAgo(days), nil)
+	}
+	r.Commit("bob@example.com", testrepo.DaysAgo(10), nil)
+	r.Commit("bob@example.com", testrepo.DaysAgo(400), nil)
+
+	tests := []struct {
+		buckets string
+		labels  []string
+		want    map[string][]int
+	}{
+		{"30d,90d,365d", []string{"0-30d", "30-90d", "90-365d", ">365d"}, map[string][]int{
+			"alice@example.com": {2, 2, 2, 2}, // Bounds belong to the older bucket
+			"bob@example.com":   {1, 0, 0, 1},
+		}},
+		{"1w,1y", []string{"0-7d", "7-365d", ">365d"}, map[string][]int{
+			"alice@example.com": {1, 5, 2},
+			"bob@example.com":   {0, 1, 1},
+		}},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, "--recency-histogram", "--recency-buckets="+tt.buckets, "--format=json", r.Dir)
+		var rows []recencyRow
+		if err := json.Unmarshal([]byte(out), &rows); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		got := make(map[string][]int)
+		for _, row := range rows {
+			var labels []string
+			for _, b := range row.Buckets {
+				labels = append(labels, b.Label)
+				got[row.Email] = append(got[row.Email], b.Commits)
+			}
+			if !reflect.DeepEqual(labels, tt.labels) {
+				t.Errorf("%s: got buckets %v, want %v", tt.buckets, labels, tt.labels)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.buckets, got, tt.want)
+		}
+	}
+
+	for _, buckets := range []string{"90d,30d", "30d,x", ""} {
+		if _, err := parseAgeBuckets(buckets); err == nil {
+			t.Errorf("%q: expected an error", buckets)
+		}
+	}
+}
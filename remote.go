@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// scpLikeURL matches the scp-style SSH syntax git accepts, e.g. git@github.com:org/repo.git.
+var scpLikeURL = regexp.MustCompile(`^[\w.-]+@[\w.-]+:`)
+
+// isRemoteURL reports whether a repository argument names a remote to clone
+// rather than a local path.
+func isRemoteURL(arg string) bool {
+	for _, scheme := range []string{"http://", "https://", "git://", "ssh://", "file://"} {
+		if strings.HasPrefix(arg, scheme) {
+			return true
+		}
+	}
+	return scpLikeURL.MatchString(arg)
+}
+
+// cloneOptions controls how remote repository arguments are fetched.
+type cloneOptions struct {
+	Depth int    // Commits fetched per branch (0 = full history)
+	Dir   string // Reusable clone location; "" clones into a temporary directory removed at exit
+}
+
+// cloneAuth returns HTTP credentials from GITOWNER_TOKEN (and optionally
+// GITOWNER_USERNAME) for https URLs. SSH URLs use the ssh-agent, which go-git
+// picks up when no auth is given.
+func cloneAuth(url string) transport.AuthMethod {
+	token := os.Getenv("GITOWNER_TOKEN")
+	if token == "" || !strings.HasPrefix(url, "https://") {
+		return nil
+	}
+	username := os.Getenv("GITOWNER_USERNAME")
+	if username == "" {
+		username = "git" // Token-based hosts accept any non-empty username
+	}
+	return &http.BasicAuth{Username: username, Password: token}
+}
+
+// cloneDirName maps a URL to a stable relative directory under --clone-dir,
+// e.g. https://github.com/org/repo.git -> github.com/org/repo.
+func cloneDirName(url string) string {
+	name := url
+	if _, rest, ok := strings.Cut(name, "://"); ok {
+		name = rest
+	}
+	if at := strings.Index(name, "@"); at >= 0 && at < strings.IndexAny(name+"/", ":/") {
+		name = name[at+1:] // Drop user info
+	}
+	name = strings.Replace(name, ":", "/", 1)
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "/"), ".git")
+	name = filepath.Clean(filepath.Join("/", filepath.FromSlash(name)))[1:] // No escaping the clone dir
+	if name == "" {
+		return "repo"
+	}
+	return name
+}
+
+// cloneRemote mirrors a remote repository to disk and returns the local path
+// to analyze. With a reusable directory an existing mirror is fetched instead
+// of cloned again.
+func cloneRemote(url string, co cloneOptions) (string, error) {
+	auth := cloneAuth(url)
+	if co.Dir == "" {
+		tmp, err := os.MkdirTemp("", "gitowner-clone-")
+		if err != nil {
+			return "", err
+		}
+		atExit(func() { os.RemoveAll(tmp) })
+		co.Dir = tmp
+	}
+	dir := filepath.Join(co.Dir, cloneDirName(url))
+
+	if repo, err := git.PlainOpen(dir); err == nil {
+		owner.Infof("Updating %s in %s...", url, dir)
+		err = repo.Fetch(&git.FetchOptions{Depth: co.Depth, Auth: auth, Force: true})
+		if err != nil && err != git.NoErrAlreadyUpToDate {
+			return "", fmt.Errorf("failed to fetch %s: %w", url, err)
+		}
+		return dir, nil
+	}
+
+	owner.Infof("Cloning %s into %s...", url, dir)
+	_, err := git.PlainClone(dir, true, &git.CloneOptions{URL: url, Depth: co.Depth, Auth: auth, Mirror: true})
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to clone %s: %w", url, err)
+	}
+	return dir, nil
+}
+
+// resolveRemotes replaces every URL argument by the path of its clone. URLs
+// that cannot be cloned are kept, so the scan reports them as failed
+// repositories like any other unreadable path.
+func resolveRemotes(args []string, co cloneOptions) []string {
+	paths := make([]string, len(args))
+	for i, arg := range args {
+		paths[i] = arg
+		if !isRemoteURL(arg) {
+			continue
+		}
+		dir, err := cloneRemote(arg, co)
+		if err != nil {
+			owner.Warnf("Warning: %v", err)
+			continue
+		}
+		paths[i] = dir
+	}
+	return paths
+}
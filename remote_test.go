@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestIsRemoteURL(t *testing.T) {
+	for arg, want := range map[string]bool{
+		"https://github.com/org/repo.git": true,
+		"git@github.com:org/repo.git":     true,
+		"file:///srv/repo.git":            true,
+		"ssh://git@host/repo":             true,
+		"../repo":                         false,
+		"/srv/repo.git":                   false,
+		"C:/src/repo":                     false,
+	} {
+		if got := isRemoteURL(arg); got != want {
+			t.Errorf("isRemoteURL(%q) = %v, want %v", arg, got, want)
+		}
+	}
+}
+
+func TestCloneDirName(t *testing.T) {
+	for url, want := range map[string]string{
+		"https://github.com/org/repo.git":   "github.com/org/repo",
+		"git@github.com:org/repo.git":       "github.com/org/repo",
+		"https://user@host.example.com/a/b": "host.example.com/a/b",
+		"file:///../../etc":                 "etc",
+	} {
+		if got := cloneDirName(url); got != want {
+			t.Errorf("cloneDirName(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestCloneFileURL(t *testing.T) {
+	r := testrepo.NewBare(t)
+	r.Commit("alice@example.com", testrepo.DaysAgo(10), nil)
+	r.Commit("bob@example.com", testrepo.DaysAgo(1), nil)
+	r.Commit("bob@example.com", testrepo.DaysAgo(2), nil)
+
+	out := mustRun(t, "--format=compact", "file://"+r.Dir)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "bob@example.com ") || !strings.HasPrefix(lines[1], "alice@example.com ") {
+		t.Errorf("unexpected ranking of the cloned repository:\n%s", out)
+	}
+}
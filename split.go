@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// runSplitTopLevel prints a separate owner ranking for every top-level
+// directory, scoping each scan to that directory via PathPrefixes. It returns
+// the repositories that failed to process in any of the scans.
+func runSplitTopLevel(ctx context.Context, repoPaths []string, opts owner.ScanOptions, rank owner.RankOptions, count int, format string) []string {
+	scopes := owner.TopLevelScopes(repoPaths, opts.Ref)
+	if len(scopes) == 0 {
+		owner.Warnf("No top-level directories found in the HEAD tree.")
+		return repoPaths
+	}
+	failedSet := make(map[string]struct{})
+	out := outputOptions{Sampling: opts.SampleRate < 1, MultiRepo: len(repoPaths) > 1}
+	for i, scope := range scopes {
+		opts.PathPrefixes = []string{scope}
+		data, failed := owner.ScanRepos(ctx, repoPaths, opts)
+		for _, repoPath := range failed {
+			failedSet[repoPath] = struct{}{}
+		}
+		owners, _ := owner.RankOwners(data, rank)
+		owners = owner.TopN(owners, count)
+
+		if format == "markdown" {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("### %s\n\n", escapeMarkdownCell(scope))
+			if len(owners) == 0 {
+				fmt.Println("_No commits found._")
+				continue
+			}
+			printMarkdown(owners, out)
+			continue
+		}
+
+		fmt.Printf("\n--- %s ---\n", scope)
+		if len(owners) == 0 {
+			fmt.Println("No commits found.")
+			continue
+		}
+		printText(owners, out)
+	}
+
+	failed := make([]string, 0, len(failedSet))
+	for repoPath := range failedSet {
+		failed = append(failed, repoPath)
+	}
+	sort.Strings(failed)
+	return failed
+}
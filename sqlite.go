@@ -0,0 +1,175 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure-Go driver, registers "sqlite"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// sqliteSchemaVersion is stored in the meta table. It only changes when the
+// schema below changes incompatibly, so queries written against it keep working.
+const sqliteSchemaVersion = 1
+
+// sqliteSchema is the layout written by --sqlite-out. Times are RFC 3339 in UTC.
+const sqliteSchema = `
+CREATE TABLE meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE owners (
+	rank         INTEGER NOT NULL,
+	email        TEXT PRIMARY KEY,
+	name         TEXT NOT NULL,
+	score        REAL NOT NULL,
+	raw_score    REAL NOT NULL,
+	repo_count   INTEGER NOT NULL,
+	commit_count INTEGER NOT NULL,
+	last_active  TEXT NOT NULL,
+	home_repo    TEXT NOT NULL,
+	active_days  INTEGER NOT NULL
+);
+CREATE TABLE commits (
+	hash        TEXT NOT NULL,
+	repo        TEXT NOT NULL,
+	email       TEXT NOT NULL,
+	name        TEXT NOT NULL,
+	commit_time TEXT NOT NULL,
+	weight      REAL NOT NULL
+);
+CREATE TABLE commit_files (
+	hash TEXT NOT NULL,
+	repo TEXT NOT NULL,
+	path TEXT NOT NULL
+);
+CREATE TABLE file_owners (
+	repo  TEXT NOT NULL,
+	path  TEXT NOT NULL,
+	email TEXT NOT NULL,
+	score REAL NOT NULL
+);
+CREATE INDEX commits_email ON commits (email);
+CREATE INDEX commit_files_path ON commit_files (repo, path);
+CREATE INDEX file_owners_path ON file_owners (repo, path);
+`
+
+// writeSQLite writes the ranking, the per-commit credits and the per-file
+// owners to a new SQLite database at filePath, replacing any existing file.
+func writeSQLite(filePath string, owners []owner.OwnerScore, data *owner.Data, params []parameter) error {
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	db, err := sql.Open("sqlite", filePath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	insert := func(query string, rows func(stmt *sql.Stmt) error) error {
+		stmt, err := tx.Prepare(query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		return rows(stmt)
+	}
+
+	err = insert("INSERT INTO meta (key, value) VALUES (?, ?)", func(stmt *sql.Stmt) error {
+		if _, err := stmt.Exec("schema_version", fmt.Sprint(sqliteSchemaVersion)); err != nil {
+			return err
+		}
+		for _, p := range params {
+			if _, err := stmt.Exec(p.Key, p.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write meta: %w", err)
+	}
+
+	err = insert("INSERT INTO owners VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", func(stmt *sql.Stmt) error {
+		for i, o := range owners {
+			if _, err := stmt.Exec(i+1, o.Email, o.Name, o.Score, o.RawScore, o.RepoCount, o.CommitCount,
+				o.LastActive.UTC().Format(time.RFC3339), o.HomeRepo, o.ActiveDays); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write owners: %w", err)
+	}
+
+	err = insert("INSERT INTO commits VALUES (?, ?, ?, ?, ?, ?)", func(stmt *sql.Stmt) error {
+		for _, c := range data.CommitLog {
+			if _, err := stmt.Exec(c.Hash, c.Repo, c.Email, c.Name, c.When.UTC().Format(time.RFC3339), c.Weight); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write commits: %w", err)
+	}
+
+	err = insert("INSERT INTO commit_files VALUES (?, ?, ?)", func(stmt *sql.Stmt) error {
+		// A commit credited to several identities lists its files once
+		written := make(map[[2]string]struct{})
+		for _, c := range data.CommitLog {
+			key := [2]string{c.Repo, c.Hash}
+			if _, ok := written[key]; ok {
+				continue
+			}
+			written[key] = struct{}{}
+			for _, path := range c.Paths {
+				if _, err := stmt.Exec(c.Hash, c.Repo, path); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write commit files: %w", err)
+	}
+
+	err = insert("INSERT INTO file_owners VALUES (?, ?, ?, ?)", func(stmt *sql.Stmt) error {
+		keys := make([]owner.FileKey, 0, len(data.Files))
+		for key := range data.Files {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].Repo != keys[j].Repo {
+				return keys[i].Repo < keys[j].Repo
+			}
+			return keys[i].Path < keys[j].Path
+		})
+		for _, key := range keys {
+			for email, score := range data.Files[key] {
+				if _, err := stmt.Exec(key.Repo, key.Path, email, score); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write file owners: %w", err)
+	}
+	return tx.Commit()
+}
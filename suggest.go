@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// defaultSuggestTemplate is the reviewer-suggestion message used when --suggest-template is not given.
+const defaultSuggestTemplate = "Suggested reviewers based on recent ownership: {reviewers}"
+
+// --- Structure for the TOML Usernames File ---
+type UsernameConfig struct {
+	Usernames map[string]string `toml:"usernames"` // email -> handle (without the leading @)
+}
+
+// loadUsernames loads an email -> handle map from a TOML file with a [usernames] table.
+func loadUsernames(filePath string) (map[string]string, error) {
+	usernames := make(map[string]string)
+	if filePath == "" {
+		return usernames, nil
+	}
+	var config UsernameConfig
+	if _, err := toml.DecodeFile(filePath, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse usernames file %s: %w: %w", filePath, owner.ErrParse, err)
+	}
+	for email, handle := range config.Usernames {
+		email = strings.ToLower(strings.TrimSpace(email))
+		handle = strings.TrimPrefix(strings.TrimSpace(handle), "@")
+		if email != "" && handle != "" {
+			usernames[email] = handle
+		}
+	}
+	return usernames, nil
+}
+
+// readPathList reads one path per line from a file ("-" for stdin), skipping
+// blank lines and lines starting with '#'.
+func readPathList(filePath string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if filePath != "-" {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, strings.TrimPrefix(line, "./"))
+	}
+	return paths, scanner.Err()
+}
+
+// reviewerHandle returns the @handle for an owner: the username map entry if
+// there is one, the login embedded in a GitHub noreply address, or else the
+// plain email.
+func reviewerHandle(email string, usernames map[string]string) string {
+	if handle, ok := usernames[email]; ok {
+		return "@" + handle
+	}
+	if local, domain, ok := strings.Cut(email, "@"); ok && domain == "users.noreply.github.com" {
+		if _, login, hasID := strings.Cut(local, "+"); hasID {
+			return "@" + login
+		}
+		return "@" + local
+	}
+	return email
+}
+
+// renderSuggestion fills the reviewer-suggestion template. Supported
+// placeholders are {reviewers} (all handles, comma separated), {reviewer1},
+// {reviewer2}, ... (individual handles by rank) and {count}.
+func renderSuggestion(template string, owners []owner.OwnerScore, usernames map[string]string) string {
+	handles := make([]string, len(owners))
+	replacements := make([]string, 0, 2*len(owners)+4)
+	for i, owner := range owners {
+		handles[i] = reviewerHandle(owner.Email, usernames)
+		replacements = append(replacements, "{reviewer"+strconv.Itoa(i+1)+"}", handles[i])
+	}
+	replacements = append(replacements,
+		"{reviewers}", strings.Join(handles, ", "),
+		"{count}", strconv.Itoa(len(owners)))
+	return strings.NewReplacer(replacements...).Replace(template)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// aliasSimilarity holds the tunable thresholds of --suggest-aliases.
+type aliasSimilarity struct {
+	MaxDistance int // Largest edit distance between two addresses (0 disables the check)
+	MinLocal    int // Shortest local part compared by local part or edit distance
+}
+
+// aliasSuggestion is one email suggested as an alias of a canonical email.
+type aliasSuggestion struct {
+	Alias  string
+	Reason string
+}
+
+// suggestAliases groups owners that look like the same person: the same
+// local part at different domains, the same full name (at least two words),
+// or addresses within MaxDistance edits that are not just numbered variants.
+// Each group is suggested under its owner with the most commits (ties go to
+// the alphabetically first email). It returns the suggestions by canonical
+// email.
+func suggestAliases(owners []owner.OwnerScore, sim aliasSimilarity) map[string][]aliasSuggestion {
+	parent := make([]int, len(owners))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	reasons := make(map[[2]int]string)
+	for i := range owners {
+		for j := i + 1; j < len(owners); j++ {
+			if reason := aliasReason(owners[i], owners[j], sim); reason != "" {
+				reasons[[2]int{i, j}] = reason
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := range owners {
+		groups[find(i)] = append(groups[find(i)], i)
+	}
+	suggestions := make(map[string][]aliasSuggestion)
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Slice(members, func(a, b int) bool {
+			oa, ob := owners[members[a]], owners[members[b]]
+			if oa.CommitCount != ob.CommitCount {
+				return oa.CommitCount > ob.CommitCount
+			}
+			return oa.Email < ob.Email
+		})
+		canonical := owners[members[0]].Email
+		for _, m := range members[1:] {
+			// Explain each alias by its first matching pair within the group
+			reason := ""
+			for _, other := range members {
+				pair := [2]int{min(m, other), max(m, other)}
+				if r, ok := reasons[pair]; ok {
+					reason = r + " as " + owners[other].Email
+					break
+				}
+			}
+			suggestions[canonical] = append(suggestions[canonical], aliasSuggestion{Alias: owners[m].Email, Reason: reason})
+		}
+	}
+	return suggestions
+}
+
+// aliasReason explains why two owners look like the same person, or returns
+// "" if they do not.
+func aliasReason(a, b owner.OwnerScore, sim aliasSimilarity) string {
+	localA, domainA, okA := strings.Cut(a.Email, "@")
+	localB, domainB, okB := strings.Cut(b.Email, "@")
+	longEnough := okA && okB && len(localA) >= sim.MinLocal && len(localB) >= sim.MinLocal
+	switch {
+	case longEnough && localA == localB && domainA != domainB:
+		return "same local part"
+	case fullName(a.Name) != "" && fullName(a.Name) == fullName(b.Name):
+		return "same name"
+	case longEnough && sim.MaxDistance > 0 && !numberedVariants(a.Email, b.Email) && editDistance(a.Email, b.Email, sim.MaxDistance) <= sim.MaxDistance:
+		return "similar address"
+	}
+	return ""
+}
+
+// numberedVariants reports whether two addresses differ only in their
+// digits, like user1@ and user2@, which are numbered accounts rather than
+// typos of each other.
+func numberedVariants(a, b string) bool {
+	dropDigits := func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return -1
+		}
+		return r
+	}
+	return strings.Map(dropDigits, a) == strings.Map(dropDigits, b)
+}
+
+// fullName normalizes a name for comparison (case and spacing ignored), or
+// returns "" for single-word names, which are too often shared ("admin").
+func fullName(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+	if len(words) < 2 {
+		return ""
+	}
+	return strings.Join(words, " ")
+}
+
+// editDistance returns the Levenshtein distance between a and b, or limit+1
+// as soon as it is known to exceed limit.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		best := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			best = min(best, cur[j])
+		}
+		if best > limit {
+			return limit + 1
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// printAliasSuggestions prints the suggestions as an [aliases] table ready to
+// paste into an aliases file, with the reason for each alias as a comment.
+func printAliasSuggestions(suggestions map[string][]aliasSuggestion) {
+	if len(suggestions) == 0 {
+		fmt.Println("# No likely aliases found.")
+		return
+	}
+	canonicals := make([]string, 0, len(suggestions))
+	for canonical := range suggestions {
+		canonicals = append(canonicals, canonical)
+	}
+	sort.Strings(canonicals)
+	fmt.Println("# Suggested aliases: review every entry before using this file.")
+	fmt.Println("[aliases]")
+	for _, canonical := range canonicals {
+		fmt.Printf("%q = [\n", canonical)
+		for _, s := range suggestions[canonical] {
+			fmt.Printf("  %q, # %s\n", s.Alias, s.Reason)
+		}
+		fmt.Println("]")
+	}
+}
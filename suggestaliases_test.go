@@ -0,0 +1,57 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mateobur/gitowner/internal/testrepo"
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+func TestSuggestAliases(t *testing.T) {
+	owners := []owner.OwnerScore{
+		{Email: "jdoe@example.com", Name: "jdoe", CommitCount: 10},
+		{Email: "jdoe@personal.example.org", Name: "jdoe", CommitCount: 2},     // Same local part
+		{Email: "jane.doe@old.example.com", Name: "Jane  Doe", CommitCount: 1}, // Same name as the next
+		{Email: "jane@example.net", Name: "jane doe", CommitCount: 3},
+		{Email: "mary.smith@example.com", Name: "Mary", CommitCount: 5},
+		{Email: "mary.smtih@example.com", Name: "Mary", CommitCount: 1}, // A typo
+		// Clearly distinct: numbered accounts, short local parts, single shared names
+		{Email: "build1@example.com", Name: "ci", CommitCount: 4},
+		{Email: "build2@example.com", Name: "ci", CommitCount: 4},
+		{Email: "al@example.com", Name: "Al", CommitCount: 1},
+		{Email: "al@other.example.com", Name: "Al", CommitCount: 1},
+		{Email: "bob@example.com", Name: "Bob", CommitCount: 1},
+	}
+	got := suggestAliases(owners, aliasSimilarity{MaxDistance: 2, MinLocal: 4})
+	want := map[string][]aliasSuggestion{
+		"jdoe@example.com":       {{"jdoe@personal.example.org", "same local part as jdoe@example.com"}},
+		"jane@example.net":       {{"jane.doe@old.example.com", "same name as jane@example.net"}},
+		"mary.smith@example.com": {{"mary.smtih@example.com", "similar address as mary.smith@example.com"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// A zero distance turns the edit distance check off
+	if got := suggestAliases(owners[4:6], aliasSimilarity{MaxDistance: 0, MinLocal: 4}); len(got) != 0 {
+		t.Errorf("edit distance disabled: got %v", got)
+	}
+}
+
+func TestSuggestAliasesOutput(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("devon@example.com", testrepo.DaysAgo(2), nil)
+	r.Commit("devon@example.com", testrepo.DaysAgo(1), nil)
+	r.Commit("devon@laptop.local", testrepo.DaysAgo(1), nil)
+	r.Commit("other@example.com", testrepo.DaysAgo(1), nil)
+
+	var file struct{ Aliases map[string][]string }
+	if _, err := toml.Decode(mustRun(t, "--suggest-aliases", r.Dir), &file); err != nil {
+		t.Fatalf("output is not valid TOML: %v", err)
+	}
+	if want := map[string][]string{"devon@example.com": {"devon@laptop.local"}}; !reflect.DeepEqual(file.Aliases, want) {
+		t.Errorf("got %v, want %v", file.Aliases, want)
+	}
+}
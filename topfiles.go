@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// hotFile is a file's total decayed activity and its dominant owner.
+type hotFile struct {
+	Repo       string  `json:"repo"`
+	Path       string  `json:"path"`
+	Activity   float64 `json:"activity"` // Sum of every owner's score on the file
+	Owner      string  `json:"owner"`
+	OwnerScore float64 `json:"owner_score"`
+	Share      float64 `json:"share"` // OwnerScore / Activity
+}
+
+// topFiles ranks the tracked files by total activity, keeping at most count.
+// Ties are broken by repository and path.
+func topFiles(files map[owner.FileKey]map[string]float64, count int) []hotFile {
+	owners := make(map[owner.FileKey]fileOwner)
+	for _, fo := range topFileOwners(files) {
+		owners[fo.Key] = fo
+	}
+	result := make([]hotFile, 0, len(owners))
+	for key, fo := range owners {
+		total := 0.0
+		for _, weight := range files[key] {
+			total += weight
+		}
+		hf := hotFile{Repo: key.Repo, Path: key.Path, Activity: total, Owner: fo.Email, OwnerScore: fo.Score}
+		if total > 0 {
+			hf.Share = fo.Score / total
+		}
+		result = append(result, hf)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Activity != result[j].Activity {
+			return result[i].Activity > result[j].Activity
+		}
+		if result[i].Repo != result[j].Repo {
+			return result[i].Repo < result[j].Repo
+		}
+		return result[i].Path < result[j].Path
+	})
+	if len(result) > count {
+		result = result[:count]
+	}
+	return result
+}
+
+// printTopFilesText prints one line per file, joined with its repository
+// argument so the path resolves from the working directory.
+func printTopFilesText(files []hotFile) {
+	fmt.Println("\n--- Most Active Files ---")
+	if len(files) == 0 {
+		fmt.Println("No files found.")
+		return
+	}
+	for i, f := range files {
+		fmt.Printf("%d. %s (Activity: %.2f, Owner: %s, %.0f%%)\n", i+1, filepath.Join(f.Repo, f.Path), f.Activity, f.Owner, f.Share*100)
+	}
+}
+
+// printTopFilesMarkdown prints the files as a Markdown table.
+func printTopFilesMarkdown(files []hotFile) {
+	fmt.Println("| Rank | File | Activity | Owner | Share |")
+	fmt.Println("|---:|---|---:|---|---:|")
+	for i, f := range files {
+		fmt.Printf("| %d | %s | %.2f | %s | %.0f%% |\n", i+1, escapeMarkdownCell(filepath.Join(f.Repo, f.Path)), f.Activity, escapeMarkdownCell(f.Owner), f.Share*100)
+	}
+}
+
+// printTopFilesJSON prints the files as a JSON array.
+func printTopFilesJSON(files []hotFile) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(files)
+}
+
+// printTopFilesCSV prints the files as CSV with a header row.
+func printTopFilesCSV(files []hotFile) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"rank", "repo", "path", "activity", "owner", "owner_score", "share"})
+	float := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	for i, f := range files {
+		w.Write([]string{strconv.Itoa(i + 1), f.Repo, f.Path, float(f.Activity), f.Owner, float(f.OwnerScore), float(f.Share)})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestTopFiles(t *testing.T) {
+	r := testrepo.New(t)
+	for i := 0; i < 4; i++ {
+		r.Commit("alice@example.com", testrepo.DaysAgo(10), map[string]string{"hot.go": testrepo.Lines(i + 1)})
+	}
+	r.Commit("bob@example.com", testrepo.DaysAgo(10), map[string]string{"hot.go": testrepo.Lines(9), "cold.go": "cold\n"})
+	for i := 0; i < 6; i++ {
+		r.Commit("carol@example.com", testrepo.DaysAgo(10), map[string]string{"vendor/lib.go": strconv.Itoa(i)})
+	}
+
+	out := mustRun(t, "--top-files", "--format=json", "--ignore-paths=vendor/**", r.Dir)
+	var files []hotFile
+	if err := json.Unmarshal([]byte(out), &files); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %+v, want hot.go and cold.go", files)
+	}
+	if hot := files[0]; hot.Path != "hot.go" || hot.Owner != "alice@example.com" || !near(hot.Share, 0.8) {
+		t.Errorf("got %+v first, want hot.go owned 80%% by alice", hot)
+	}
+	if cold := files[1]; cold.Path != "cold.go" || cold.Owner != "bob@example.com" || cold.Activity >= files[0].Activity {
+		t.Errorf("got %+v second, want cold.go owned by bob", cold)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// trendEntry is one owner's score in the earlier window (A) and the recent
+// window (B) of --compare.
+type trendEntry struct {
+	Email     string  `json:"email"`
+	ScoreA    float64 `json:"score_a"`
+	ScoreB    float64 `json:"score_b"`
+	Delta     float64 `json:"delta"`     // ScoreB - ScoreA
+	Direction string  `json:"direction"` // "up", "down" or "flat"
+}
+
+// compareWindows splits the time before now into the recent window B (the
+// last windowB) and the window A right before it (windowA long).
+func compareWindows(now time.Time, windowA, windowB time.Duration) (aStart, bStart time.Time) {
+	bStart = now.Add(-windowB)
+	return bStart.Add(-windowA), bStart
+}
+
+// runCompare scores every repository once per window and returns the owners
+// whose score changed the most, at most count of them, sorted by descending
+// delta. Each window is scored as of its own end, so decay does not penalize
+// the earlier one. It also returns the repositories that failed in either scan.
+func runCompare(ctx context.Context, repoPaths []string, opts owner.ScanOptions, rank owner.RankOptions, windowA, windowB time.Duration, count int) ([]trendEntry, []string) {
+	aStart, bStart := compareWindows(opts.Now, windowA, windowB)
+	scoreWindow := func(since, until time.Time) (map[string]float64, []string) {
+		windowOpts, windowRank := opts, rank
+		windowOpts.Since, windowOpts.Until, windowOpts.Now = since, until, until
+		windowRank.Now = until
+		data, failed := owner.ScanRepos(ctx, repoPaths, windowOpts)
+		owners, _ := owner.RankOwners(data, windowRank)
+		scores := make(map[string]float64, len(owners))
+		for _, o := range owners {
+			scores[o.Email] = o.Score
+		}
+		return scores, failed
+	}
+	owner.Infof("Scoring window A: %s to %s.", aStart.Format(time.DateOnly), bStart.Format(time.DateOnly))
+	scoresA, failedA := scoreWindow(aStart, bStart)
+	owner.Infof("Scoring window B: %s to %s.", bStart.Format(time.DateOnly), opts.Now.Format(time.DateOnly))
+	scoresB, failedB := scoreWindow(bStart, opts.Now)
+
+	entries := make([]trendEntry, 0, len(scoresA)+len(scoresB))
+	for email, a := range scoresA {
+		entries = append(entries, newTrendEntry(email, a, scoresB[email]))
+	}
+	for email, b := range scoresB {
+		if _, ok := scoresA[email]; !ok {
+			entries = append(entries, newTrendEntry(email, 0, b))
+		}
+	}
+	// Keep the biggest movers in either direction, then list them rising first
+	sort.Slice(entries, func(i, j int) bool {
+		if di, dj := math.Abs(entries[i].Delta), math.Abs(entries[j].Delta); di != dj {
+			return di > dj
+		}
+		return entries[i].Email < entries[j].Email
+	})
+	if len(entries) > count {
+		entries = entries[:count]
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Delta > entries[j].Delta })
+
+	failedSet := make(map[string]struct{})
+	for _, repoPath := range append(failedA, failedB...) {
+		failedSet[repoPath] = struct{}{}
+	}
+	failed := make([]string, 0, len(failedSet))
+	for repoPath := range failedSet {
+		failed = append(failed, repoPath)
+	}
+	sort.Strings(failed)
+	return entries, failed
+}
+
+// trendEpsilon is the score change below which an owner counts as flat.
+const trendEpsilon = 0.005
+
+// newTrendEntry compares an owner's scores in the two windows.
+func newTrendEntry(email string, a, b float64) trendEntry {
+	e := trendEntry{Email: email, ScoreA: a, ScoreB: b, Delta: b - a, Direction: "flat"}
+	if e.Delta > trendEpsilon {
+		e.Direction = "up"
+	} else if e.Delta < -trendEpsilon {
+		e.Direction = "down"
+	}
+	return e
+}
+
+// trendArrow is the text-mode marker of a direction.
+func trendArrow(direction string) string {
+	switch direction {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	}
+	return "="
+}
+
+// printTrendText prints one line per owner.
+func printTrendText(entries []trendEntry, windowA, windowB string) {
+	fmt.Printf("\n--- Owner Trend (A: %s before B, B: last %s) ---\n", windowA, windowB)
+	if len(entries) == 0 {
+		fmt.Println("No commits found in either window.")
+		return
+	}
+	for i, e := range entries {
+		fmt.Printf("%d. %s %s (A: %.2f, B: %.2f, Delta: %+.2f)\n", i+1, trendArrow(e.Direction), e.Email, e.ScoreA, e.ScoreB, e.Delta)
+	}
+}
+
+// printTrendMarkdown prints the entries as a Markdown table.
+func printTrendMarkdown(entries []trendEntry, windowA, windowB string) {
+	fmt.Printf("**Owner trend** (A: %s before B, B: last %s)\n\n", windowA, windowB)
+	fmt.Println("| Rank | Email | Score A | Score B | Delta | Trend |")
+	fmt.Println("|---:|---|---:|---:|---:|---|")
+	for i, e := range entries {
+		fmt.Printf("| %d | %s | %.2f | %.2f | %+.2f | %s |\n", i+1, escapeMarkdownCell(e.Email), e.ScoreA, e.ScoreB, e.Delta, e.Direction)
+	}
+}
+
+// printTrendJSON prints the entries as a JSON array.
+func printTrendJSON(entries []trendEntry) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// printTrendCSV prints the entries as CSV with a header row.
+func printTrendCSV(entries []trendEntry) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"rank", "email", "score_a", "score_b", "delta", "direction"})
+	float := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	for i, e := range entries {
+		w.Write([]string{strconv.Itoa(i + 1), e.Email, float(e.ScoreA), float(e.ScoreB), float(e.Delta), e.Direction})
+	}
+	w.Flush()
+	return w.Error()
+}
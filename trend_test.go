@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mateobur/gitowner/internal/testrepo"
+)
+
+func TestCompareTrend(t *testing.T) {
+	r := testrepo.New(t)
+	r.Commit("early@example.com", testrepo.DaysAgo(50), nil)
+	r.Commit("early@example.com", testrepo.DaysAgo(45), nil)
+	r.Commit("steady@example.com", testrepo.DaysAgo(40), nil) // Both commits are 10 days old at the end of their window
+	r.Commit("steady@example.com", testrepo.DaysAgo(10), nil)
+	r.Commit("recent@example.com", testrepo.DaysAgo(5), nil)
+	r.Commit("recent@example.com", testrepo.DaysAgo(2), nil)
+
+	out := mustRun(t, "--compare", "--window-a=30d", "--window-b=30d", "--format=json", r.Dir)
+	var entries []trendEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	want := []struct{ email, direction string }{
+		{"recent@example.com", "up"},
+		{"steady@example.com", "flat"},
+		{"early@example.com", "down"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %+v, want %v", entries, want)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Email != w.email || e.Direction != w.direction {
+			t.Errorf("entry %d: got %s %s, want %s %s", i, e.Email, e.Direction, w.email, w.direction)
+		}
+	}
+	if e := entries[0]; e.ScoreA != 0 || e.Delta != e.ScoreB {
+		t.Errorf("recent@example.com: got %+v, want nothing in window A", e)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mateobur/gitowner/pkg/owner"
+)
+
+// defaultOrphanThreshold is the score an area's best remaining owner needs
+// for the area not to count as orphaned (see --orphan-threshold).
+const defaultOrphanThreshold = 0.25
+
+// Kinds of areas compared by the removal simulation, from coarsest to finest.
+const (
+	areaRepo = "repo"
+	areaDir  = "dir"
+	areaFile = "file"
+)
+
+// impact describes how one area's ownership changes when a contributor is removed.
+type impact struct {
+	Kind       string
+	Area       string
+	Before     string  // Top owner with everyone's commits
+	After      string  // Top owner without the removed contributor ("" if nobody is left)
+	AfterScore float64 // Score of After in the area
+	Orphaned   bool    // No remaining owner reaches the threshold
+}
+
+// areaScores sums the tracked per-file weights into every area they belong
+// to: the file itself, its top-level directory and its repository. Keys are
+// "kind\x00area".
+func areaScores(data *owner.Data) map[string]map[string]float64 {
+	areas := make(map[string]map[string]float64)
+	add := func(kind, area string, weights map[string]float64) {
+		key := kind + "\x00" + area
+		if _, ok := areas[key]; !ok {
+			areas[key] = make(map[string]float64)
+		}
+		for email, w := range weights {
+			areas[key][email] += w
+		}
+	}
+	for key, weights := range data.Files {
+		add(areaFile, filepath.Join(key.Repo, key.Path), weights)
+		if dir, _, ok := strings.Cut(key.Path, "/"); ok {
+			add(areaDir, filepath.Join(key.Repo, dir)+"/", weights)
+		}
+	}
+	for email, repos := range data.RepoScores {
+		for repo, w := range repos {
+			add(areaRepo, repo, map[string]float64{email: w})
+		}
+	}
+	return areas
+}
+
+// topAreaOwner returns the highest-scoring owner of an area, ties broken by email.
+func topAreaOwner(weights map[string]float64) (string, float64) {
+	best, bestScore := "", 0.0
+	for email, w := range weights {
+		if w > bestScore || (w == bestScore && email < best) {
+			best, bestScore = email, w
+		}
+	}
+	return best, bestScore
+}
+
+// removalImpact compares the areas of a normal scan (before) with a scan that
+// excluded the removed contributor (after). It returns the areas that would
+// be orphaned or change top owner, sorted by kind and area.
+func removalImpact(before, after *owner.Data, threshold float64) []impact {
+	afterAreas := areaScores(after)
+	var impacts []impact
+	for key, weights := range areaScores(before) {
+		kind, area, _ := strings.Cut(key, "\x00")
+		top, score := topAreaOwner(weights)
+		newTop, newScore := topAreaOwner(afterAreas[key])
+		// Areas that were already below the threshold are only orphaned once nobody is left
+		orphaned := newTop == "" || (score >= threshold && newScore < threshold)
+		if !orphaned && newTop == top {
+			continue
+		}
+		impacts = append(impacts, impact{Kind: kind, Area: area, Before: top, After: newTop, AfterScore: newScore, Orphaned: orphaned})
+	}
+	sortByArea(impacts, func(im impact) (string, string) { return im.Kind, im.Area })
+	return impacts
+}
+
+// printRemovalImpact prints the at-risk areas: first the orphaned ones, then
+// those that would get a new top owner.
+func printRemovalImpact(removed string, impacts []impact, threshold float64) {
+	fmt.Printf("\n--- Impact of removing %s ---\n", removed)
+	if len(impacts) == 0 {
+		fmt.Println("No repository, directory or file would be orphaned or change top owner.")
+		return
+	}
+	orphaned, reassigned := 0, 0
+	for _, im := range impacts {
+		if im.Orphaned {
+			orphaned++
+		} else {
+			reassigned++
+		}
+	}
+
+	fmt.Printf("\nOrphaned (no remaining owner with score >= %.2f): %d\n", threshold, orphaned)
+	for _, im := range impacts {
+		if !im.Orphaned {
+			continue
+		}
+		if im.After == "" {
+			fmt.Printf("  [%s] %s (was %s; nobody left)\n", im.Kind, im.Area, im.Before)
+		} else {
+			fmt.Printf("  [%s] %s (was %s; best left: %s, %.2f)\n", im.Kind, im.Area, im.Before, im.After, im.AfterScore)
+		}
+	}
+
+	fmt.Printf("\nNew top owner: %d\n", reassigned)
+	for _, im := range impacts {
+		if !im.Orphaned {
+			fmt.Printf("  [%s] %s: %s -> %s (%.2f)\n", im.Kind, im.Area, im.Before, im.After, im.AfterScore)
+		}
+	}
+}
+
+// orphan is an area whose significant contributors have all gone inactive.
+type orphan struct {
+	Kind       string
+	Area       string
+	Top        string    // Strongest historical owner
+	LastActive time.Time // Most recent commit by any of the area's significant contributors
+}
+
+// findOrphans lists the areas whose contributors scoring at least threshold
+// (or, if none does, whose top owner) all made their last commit, anywhere,
+// before cutoff. Sorted by kind and area.
+func findOrphans(data *owner.Data, threshold float64, cutoff time.Time) []orphan {
+	var orphans []orphan
+	for key, weights := range areaScores(data) {
+		kind, area, _ := strings.Cut(key, "\x00")
+		top, _ := topAreaOwner(weights)
+		if top == "" {
+			continue
+		}
+		latest := data.LastActive[top]
+		for email, w := range weights {
+			if w >= threshold && data.LastActive[email].After(latest) {
+				latest = data.LastActive[email]
+			}
+		}
+		if latest.Before(cutoff) {
+			orphans = append(orphans, orphan{Kind: kind, Area: area, Top: top, LastActive: latest})
+		}
+	}
+	sortByArea(orphans, func(o orphan) (string, string) { return o.Kind, o.Area })
+	return orphans
+}
+
+// printOrphans prints the orphaned areas with the last time any significant contributor was active.
+func printOrphans(orphans []orphan, cutoff time.Time) {
+	fmt.Printf("\n--- Orphaned code (no significant contributor active since %s) ---\n", cutoff.UTC().Format(time.DateOnly))
+	if len(orphans) == 0 {
+		fmt.Println("No orphaned repositories, directories or files.")
+		return
+	}
+	for _, o := range orphans {
+		fmt.Printf("  [%s] %s (top owner %s, last active %s)\n", o.Kind, o.Area, o.Top, o.LastActive.UTC().Format(time.DateOnly))
+	}
+}
+
+// sortByArea sorts items from the coarsest kind of area to the finest, then by area name.
+func sortByArea[T any](items []T, key func(T) (kind, area string)) {
+	rank := map[string]int{areaRepo: 0, areaDir: 1, areaFile: 2}
+	sort.Slice(items, func(i, j int) bool {
+		ki, ai := key(items[i])
+		kj, aj := key(items[j])
+		if ki != kj {
+			return rank[ki] < rank[kj]
+		}
+		return ai < aj
+	})
+}